@@ -0,0 +1,115 @@
+package main
+
+import (
+	"InstantWellnessKits/src/config"
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// apiKeyCommand issues, lists and revokes API keys in the storage selected
+// by STORAGE.
+func apiKeyCommand(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	// The arguments are checked before connecting to the database.
+	var issueArgs struct {
+		name, tenant string
+		roles        []string
+	}
+	var revokeId uuid.UUID
+	switch args[0] {
+	case "issue":
+		fs := flag.NewFlagSet("apikey issue", flag.ContinueOnError)
+		name := fs.String("name", "", "human-readable key name")
+		tenant := fs.String("tenant", entity.DefaultTenant, "tenant the key belongs to")
+		roles := fs.String("roles", "", "comma-separated roles: analyst, order_writer, importer, rate_admin")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 0 {
+			return errUsage
+		}
+		issueArgs.name, issueArgs.tenant = *name, *tenant
+		if *roles != "" {
+			issueArgs.roles = strings.Split(*roles, ",")
+		}
+	case "list":
+		if len(args) != 1 {
+			return errUsage
+		}
+	case "revoke":
+		if len(args) != 2 {
+			return errUsage
+		}
+		var err error
+		if revokeId, err = uuid.Parse(args[1]); err != nil {
+			return fmt.Errorf("invalid key id: %w", err)
+		}
+	default:
+		return errUsage
+	}
+
+	cfg, logger, err := setup()
+	if err != nil {
+		return err
+	}
+	if cfg.Storage == config.StorageMemory {
+		return errMemoryKeys
+	}
+
+	ctx := context.Background()
+	repos, err := openRepositories(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer repos.close()
+
+	uc := usecase.NewManageAPIKeysUseCase(repos.apiKeys)
+
+	switch args[0] {
+	case "issue":
+		plaintext, key, err := uc.Issue(ctx, issueArgs.name, issueArgs.tenant, issueArgs.roles)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("id:     %s\ntenant: %s\nroles:  %v\nkey:    %s\n\n",
+			key.Id, key.TenantId, key.Roles, plaintext)
+		fmt.Println("Store the key now; it cannot be shown again.")
+		return nil
+	case "list":
+		keys, err := uc.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tTENANT\tPREFIX\tROLES\tCREATED\tREVOKED")
+		for _, key := range keys {
+			revoked := "-"
+			if key.RevokedAt != nil {
+				revoked = key.RevokedAt.Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%v\t%s\t%s\n", key.Id, key.Name, key.TenantId, key.Prefix,
+				key.Roles, key.CreatedAt.Format(time.DateTime), revoked)
+		}
+		return w.Flush()
+	default:
+		if err := uc.Revoke(ctx, revokeId); err != nil {
+			return err
+		}
+		fmt.Println("Revoked", revokeId)
+		return nil
+	}
+}
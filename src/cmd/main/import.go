@@ -0,0 +1,168 @@
+package main
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var errImportInterrupted = errors.New(`import interrupted`)
+
+// importFile runs an import in the foreground, bypassing the job queue.
+// Orders are stamped with the import id like queued imports, so a run
+// resumed with the same -id and -after replays no rows twice.
+func importFile(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	tenant := fs.String("tenant", entity.DefaultTenant, "tenant the orders belong to")
+	format := fs.String("format", "", "file format; detected from the file when empty")
+	dryRun := fs.Bool("dry-run", false, "validate, geocode and price the rows without inserting them")
+	id := fs.String("id", "", "import id to resume; a new one is generated when empty")
+	after := fs.Int("after", 0, "skip rows up to and including this one, when resuming")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+	path := fs.Arg(0)
+
+	job := entity.NewImportJob(*tenant, filepath.Base(path), *format, *dryRun)
+	if *id != "" {
+		parsed, err := uuid.Parse(*id)
+		if err != nil {
+			return fmt.Errorf("invalid import id %q", *id)
+		}
+		job.Id = parsed
+	}
+	if *after > 0 {
+		job.CheckpointRow = *after
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	cfg, logger, err := setup()
+	if err != nil {
+		return err
+	}
+	location, err := time.LoadLocation(cfg.BusinessTimezone)
+	if err != nil {
+		return err
+	}
+	geocoderApi, err := newGeocoder(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer geocoderApi.Close()
+
+	repos, err := openRepositories(context.Background(), cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer repos.close()
+
+	uc := usecase.NewImportOrdersUseCase(geocoderApi, repos.orders, repos.taxRates, location, logger)
+
+	// The first signal drains: rows in flight are finished and
+	// checkpointed. A second one kills the process.
+	draining, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-draining.Done()
+		stop()
+	}()
+
+	input, bar, err := newImportProgress(file, logger)
+	if err != nil {
+		return err
+	}
+	reporter := &terminalReporter{bar: bar, draining: draining.Done(), progress: job.Progress()}
+
+	logger.Info("Import started", "import_id", job.Id, "file", path, "checkpoint_row", job.CheckpointRow)
+	err = uc.Execute(context.Background(), job, input, reporter)
+	bar.finish(reporter.progress)
+
+	progress := reporter.progress
+	fmt.Printf("import:   %s\nread:     %d\nimported: %d\nfailed:   %d\n",
+		job.Id, progress.RowsRead, progress.RowsImported, progress.RowsFailed)
+	for _, sample := range progress.ErrorSamples {
+		fmt.Printf("  row %d: %s\n", sample.Row, sample.Message)
+	}
+	if *dryRun {
+		fmt.Println("Dry run; no orders were inserted.")
+	}
+
+	if err != nil {
+		var quotaErr *usecase.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			fmt.Printf("Geocoding quota exhausted; it resets at %s.\n", quotaErr.ResetAt.Format(time.RFC3339))
+		}
+		fmt.Printf("Resume with: main import -tenant %s -id %s -after %d %s\n",
+			job.TenantId, job.Id, progress.CheckpointRow, path)
+		if draining.Err() != nil {
+			return errImportInterrupted
+		}
+		return err
+	}
+	return nil
+}
+
+type terminalReporter struct {
+	bar      *progressBar
+	draining <-chan struct{}
+	progress entity.ImportProgress
+}
+
+func (rep *terminalReporter) Progress(progress entity.ImportProgress) {
+	rep.bar.update(progress)
+}
+
+func (rep *terminalReporter) Checkpoint(progress entity.ImportProgress) error {
+	rep.progress = progress
+	rep.bar.update(progress)
+	return nil
+}
+
+func (rep *terminalReporter) Draining() <-chan struct{} {
+	return rep.draining
+}
+
+// zipMagic starts ZIP archives, XLSX workbooks included.
+var zipMagic = []byte("PK\x03\x04")
+
+// newImportProgress returns the reader to import file from and a progress
+// bar tracking how much of it was read. Archives are read by random
+// access, which cannot be tracked, so their bar shows rows only.
+func newImportProgress(file *os.File, logger *slog.Logger) (io.Reader, *progressBar, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	magic := make([]byte, len(zipMagic))
+	if _, err := file.ReadAt(magic, 0); err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+	if bytes.Equal(magic, zipMagic) {
+		return file, newProgressBar(os.Stderr, nil, 0, logger), nil
+	}
+
+	counter := &countingReader{reader: file}
+	return counter, newProgressBar(os.Stderr, counter, info.Size(), logger), nil
+}
@@ -1,96 +1,138 @@
+// Command main runs the tax service and its one-off maintenance tasks.
+//
+//	main [serve]
+//	main migrate up|down [n]|force <version>|status
+//	main seed-rates
+//	main import [-tenant <id>] [-format <format>] [-dry-run] [-id <id> -after <row>] <file>
+//	main quote [-tenant <id>] <latitude> <longitude> <amount>
+//	main recalc [-tenant <id>] [-from <time>] [-to <time>] [-dry-run]
+//	main apikey issue -name <name> -roles <role,...> [-tenant <id>]
+//	main apikey list|revoke <id>
+//
+// Without a command it serves, so existing deployments keep working.
 package main
 
 import (
 	"InstantWellnessKits/src/config"
-	"InstantWellnessKits/src/controller"
+	"InstantWellnessKits/src/logging"
 	"InstantWellnessKits/src/repository/geocoder"
 	"InstantWellnessKits/src/repository/postgres"
-	"InstantWellnessKits/src/repository/postgres/order"
-	tax_rate "InstantWellnessKits/src/repository/postgres/tax-rate"
 	"InstantWellnessKits/src/usecase"
-	"log"
-	"net/http"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
 	"time"
-
-	"github.com/rs/cors"
+	_ "time/tzdata"
 )
 
-const (
-	writeTimeout = 15 * time.Second
-	readTimeout  = 15 * time.Second
+const usage = `usage:
+  main [serve]
+  main migrate up|down [n]|force <version>|status
+  main seed-rates
+  main import [-tenant <id>] [-format csv|json|ndjson|xlsx] [-dry-run] [-id <id> -after <row>] <file>
+  main quote [-tenant <id>] [--] <latitude> <longitude> <amount>
+  main recalc [-tenant <id>] [-from <time>] [-to <time>] [-dry-run]
+  main apikey issue -name <name> -roles <role,...> [-tenant <id>]
+  main apikey list
+  main apikey revoke <id>
+
+roles: analyst, order_writer, importer, rate_admin`
+
+var (
+	errUsage       = errors.New(usage)
+	errNotPostgres = errors.New(`this command needs STORAGE=postgres`)
+	errMemoryKeys  = errors.New(`STORAGE=memory cannot keep API keys between processes; ` +
+		`use sqlite or postgres, or authenticate with JWTs`)
 )
 
 func main() {
-	if err := run(); err != nil {
-		log.Fatal(err)
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		if errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		slog.Error("Command failed", "error", err)
+		os.Exit(1)
 	}
 }
 
-func run() error {
-	cfg, err := config.New()
-	if err != nil {
-		return err
+func run(args []string) error {
+	if len(args) == 0 {
+		return serve(nil)
 	}
 
-	router := http.NewServeMux()
-
-	geocoderApi := geocoder.NewApi(cfg.GeocodingAPIKey)
-
-	conn, err := postgres.InitDb(cfg)
-	if err != nil {
-		return err
+	switch args[0] {
+	case "serve":
+		return serve(args[1:])
+	case "migrate":
+		return migrateCommand(args[1:])
+	case "seed-rates":
+		return seedRates(args[1:])
+	case "import":
+		return importFile(args[1:])
+	case "quote":
+		return quote(args[1:])
+	case "recalc":
+		return recalc(args[1:])
+	case "apikey":
+		return apiKeyCommand(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Println(usage)
+		return nil
+	default:
+		return errUsage
 	}
-	defer conn.Close()
+}
 
-	err = postgres.ApplyMigrations(conn)
+// setup loads the configuration and installs its logger as the default.
+func setup() (*config.Config, *slog.Logger, error) {
+	cfg, err := config.New()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	err = postgres.SeedTaxRates(conn)
+	logger, err := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
+	// The default logger also carries the output of the standard log
+	// package and of code without a logger of its own.
+	slog.SetDefault(logger)
 
-	taxRateRepo := tax_rate.NewRepository(conn)
-	orderRepo := order.NewRepository(conn)
-
-	createUsecase := usecase.NewCreateOrderUseCase(geocoderApi, orderRepo, taxRateRepo)
-	listUsecase := usecase.NewListOrdersUseCase(orderRepo)
-	importUsecase := usecase.NewImportOrdersUseCase(geocoderApi, orderRepo, taxRateRepo)
-
-	importController := controller.NewImportController(importUsecase)
-	createController := controller.NewCreateController(createUsecase)
-	getController := controller.NewGetController(listUsecase)
-	healthController := controller.NewHealthController()
-
-	router.Handle("POST /orders/import", importController)
-	router.Handle("POST /orders", createController)
-	router.Handle("GET /orders", getController)
-	router.Handle("GET /health", healthController)
-
-	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Authorization", "Content-Type"},
-		AllowCredentials: true,
-		Debug:            true,
-	})
-
-	handler := c.Handler(router)
+	return cfg, logger, nil
+}
 
-	server := &http.Server{
-		Addr:         ":" + cfg.Port,
-		WriteTimeout: writeTimeout,
-		ReadTimeout:  readTimeout,
-		Handler:      handler,
+// openDb opens the Postgres database; the returned function closes it and
+// logs any error doing so.
+func openDb(cfg *config.Config, logger *slog.Logger) (*sql.DB, func(), error) {
+	if cfg.Storage != config.StoragePostgres {
+		return nil, nil, errNotPostgres
 	}
+	conn, closeDb, err := postgres.InitDb(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, func() {
+		if err := closeDb(); err != nil {
+			logger.Error("Failed to close database", "error", err)
+		}
+	}, nil
+}
 
-	log.Println("Listening on", cfg.Port)
-
-	if err := server.ListenAndServe(); err != nil {
-		return err
+// newGeocoder returns the geocoding client wrapped in the governor every
+// caller in this process shares; the governor must be closed.
+func newGeocoder(cfg *config.Config, logger *slog.Logger) (*usecase.GeocodingGovernor, error) {
+	quotaZone, err := time.LoadLocation(cfg.Geocoding.QuotaZone)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return usecase.NewGeocodingGovernor(geocoder.NewApi(cfg.GeocodingAPIKey),
+		cfg.Geocoding.PerSecond, cfg.Geocoding.DailyCap, quotaZone, logger), nil
 }
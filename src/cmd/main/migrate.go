@@ -0,0 +1,88 @@
+package main
+
+import (
+	"InstantWellnessKits/src/repository/postgres"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+// migrateCommand applies, reverts, forces or reports the schema
+// migrations. Down reverts one migration unless told how many.
+func migrateCommand(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	n := 1
+	switch {
+	case (args[0] == "down" || args[0] == "force") && len(args) == 2:
+		var err error
+		n, err = strconv.Atoi(args[1])
+		if err != nil || n < 0 || (args[0] == "down" && n == 0) {
+			return fmt.Errorf("invalid number %q", args[1])
+		}
+	case args[0] == "force" || len(args) != 1:
+		return errUsage
+	}
+
+	cfg, logger, err := setup()
+	if err != nil {
+		return err
+	}
+	conn, closeDb, err := openDb(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer closeDb()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch args[0] {
+	case "up":
+		return postgres.ApplyMigrations(ctx, conn)
+	case "down":
+		return postgres.RevertMigrations(ctx, conn, n)
+	case "force":
+		return postgres.ForceMigration(ctx, conn, n)
+	case "status":
+		version, dirty, err := postgres.MigrationVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		latest, err := postgres.LatestMigration()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("version: %d\nlatest:  %d\ndirty:   %t\n", version, latest, dirty)
+		if version < latest {
+			fmt.Printf("%d migration(s) pending\n", latest-version)
+		}
+		return nil
+	default:
+		return errUsage
+	}
+}
+
+func seedRates(args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+
+	cfg, logger, err := setup()
+	if err != nil {
+		return err
+	}
+	conn, closeDb, err := openDb(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer closeDb()
+
+	return postgres.SeedTaxRates(context.Background(), conn)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"InstantWellnessKits/src/entity"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	barWidth = 30
+
+	// A terminal is redrawn often; anything else, such as the log of a
+	// Cloud Run job, gets a progress line now and then.
+	terminalRefresh = 100 * time.Millisecond
+	logRefresh      = 10 * time.Second
+)
+
+type countingReader struct {
+	reader io.Reader
+	read   atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read.Add(int64(n))
+	return n, err
+}
+
+// progressBar shows an import's progress: how much of the file was read,
+// when known, and the row counts.
+type progressBar struct {
+	out      io.Writer
+	counter  *countingReader
+	total    int64
+	terminal bool
+	logger   *slog.Logger
+	started  time.Time
+
+	mu       sync.Mutex
+	rendered time.Time
+}
+
+func newProgressBar(out *os.File, counter *countingReader, total int64, logger *slog.Logger) *progressBar {
+	info, err := out.Stat()
+	return &progressBar{
+		out:      out,
+		counter:  counter,
+		total:    total,
+		terminal: err == nil && info.Mode()&os.ModeCharDevice != 0,
+		logger:   logger,
+		started:  time.Now(),
+	}
+}
+
+func (b *progressBar) update(progress entity.ImportProgress) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	refresh := logRefresh
+	if b.terminal {
+		refresh = terminalRefresh
+	}
+	if time.Since(b.rendered) < refresh {
+		return
+	}
+	b.rendered = time.Now()
+	b.render(progress)
+}
+
+// finish draws the final state and ends the bar's line.
+func (b *progressBar) finish(progress entity.ImportProgress) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.render(progress)
+	if b.terminal {
+		fmt.Fprintln(b.out)
+	}
+}
+
+func (b *progressBar) render(progress entity.ImportProgress) {
+	rate := float64(progress.RowsRead) / max(time.Since(b.started).Seconds(), 1)
+
+	if !b.terminal {
+		attrs := []any{"read", progress.RowsRead, "imported", progress.RowsImported,
+			"failed", progress.RowsFailed, "rows_per_second", int(rate)}
+		if fraction, ok := b.fraction(); ok {
+			attrs = append(attrs, "percent", int(fraction*100))
+		}
+		b.logger.Info("Import progress", attrs...)
+		return
+	}
+
+	bar := ""
+	if fraction, ok := b.fraction(); ok {
+		filled := int(fraction * barWidth)
+		bar = fmt.Sprintf("[%s%s] %3d%%  ", strings.Repeat("=", filled),
+			strings.Repeat(" ", barWidth-filled), int(fraction*100))
+	}
+	fmt.Fprintf(b.out, "\r%sread %d  imported %d  failed %d  %.0f rows/s\033[K",
+		bar, progress.RowsRead, progress.RowsImported, progress.RowsFailed, rate)
+}
+
+func (b *progressBar) fraction() (float64, bool) {
+	if b.counter == nil || b.total <= 0 {
+		return 0, false
+	}
+	return min(float64(b.counter.read.Load())/float64(b.total), 1), true
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// quote prints the tax for a delivery as JSON, the same body POST
+// /orders/quote returns.
+func quote(args []string) error {
+	fs := flag.NewFlagSet("quote", flag.ContinueOnError)
+	tenant := fs.String("tenant", entity.DefaultTenant, "tenant whose rates apply")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 3 {
+		return errUsage
+	}
+
+	latitude, err := strconv.ParseFloat(fs.Arg(0), 64)
+	if err != nil {
+		return fmt.Errorf("invalid latitude %q", fs.Arg(0))
+	}
+	longitude, err := strconv.ParseFloat(fs.Arg(1), 64)
+	if err != nil {
+		return fmt.Errorf("invalid longitude %q", fs.Arg(1))
+	}
+	amount, err := decimal.NewFromString(fs.Arg(2))
+	if err != nil {
+		return fmt.Errorf("invalid amount %q", fs.Arg(2))
+	}
+
+	cfg, logger, err := setup()
+	if err != nil {
+		return err
+	}
+	geocoderApi, err := newGeocoder(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer geocoderApi.Close()
+
+	repos, err := openRepositories(context.Background(), cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer repos.close()
+
+	uc := usecase.NewQuoteOrderUseCase(geocoderApi, repos.taxRates)
+	ctx := entity.ContextWithPrincipal(context.Background(), &entity.Principal{TenantId: *tenant})
+
+	result, err := uc.Execute(ctx, latitude, longitude, amount)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"InstantWellnessKits/src/controller"
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/metrics"
+	"InstantWellnessKits/src/repository/storage"
+	"InstantWellnessKits/src/tracing"
+	"InstantWellnessKits/src/usecase"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/rs/cors"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	writeTimeout = 15 * time.Second
+	readTimeout  = 15 * time.Second
+)
+
+// serve serves the API and runs imports until SIGTERM, migrating and
+// seeding the Postgres database first if AUTO_MIGRATE is set.
+func serve(args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+
+	cfg, logger, err := setup()
+	if err != nil {
+		return err
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter,
+		cfg.Tracing.OTLPEndpoint, cfg.Tracing.SampleRatio)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
+	// ctx is cancelled on SIGTERM, which Cloud Run and docker send before
+	// killing the container.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	location, err := time.LoadLocation(cfg.BusinessTimezone)
+	if err != nil {
+		return err
+	}
+
+	router := http.NewServeMux()
+
+	// Every geocoding caller goes through one governor so that concurrent
+	// imports and live traffic share a single QPS and daily budget.
+	geocoderApi, err := newGeocoder(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer geocoderApi.Close()
+
+	repos, err := openRepositories(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer repos.close()
+
+	taxRateRepo := repos.taxRates
+	orderRepo := repos.orders
+	apiKeyRepo := repos.apiKeys
+	importJobRepo := repos.importJobs
+
+	uploads, err := storage.NewLocal(cfg.Imports.StorageDir)
+	if err != nil {
+		return err
+	}
+
+	createUsecase := usecase.NewCreateOrderUseCase(geocoderApi, orderRepo, taxRateRepo, location)
+	listUsecase := usecase.NewListOrdersUseCase(orderRepo, location)
+	quoteUsecase := usecase.NewQuoteOrderUseCase(geocoderApi, taxRateRepo)
+	exportUsecase := usecase.NewExportOrdersUseCase(orderRepo)
+	timeseriesUsecase := usecase.NewTimeseriesUseCase(orderRepo, location)
+	heatmapUsecase := usecase.NewHeatmapUseCase(orderRepo)
+	taxSettingsUsecase := usecase.NewManageTaxSettingsUseCase(taxRateRepo)
+	authUsecase := usecase.NewAuthenticateUseCase(apiKeyRepo,
+		cfg.Auth.JWTSigningKey, cfg.Auth.JWTIssuer)
+	importUsecase := usecase.NewImportOrdersUseCase(geocoderApi, orderRepo, taxRateRepo, location, logger)
+	enqueueImportUsecase := usecase.NewEnqueueImportUseCase(uploads, importJobRepo, logger)
+	getImportUsecase := usecase.NewGetImportUseCase(importJobRepo)
+
+	// Imports are queued in storage and run in the background; a job left
+	// behind by a crashed replica is picked up again once its lease expires.
+	importHub := usecase.NewImportProgressHub()
+	importRunner := usecase.NewImportRunner(importJobRepo, uploads, importUsecase, importHub,
+		cfg.Imports.PollInterval, cfg.Imports.Lease, logger)
+	runnerDone := make(chan struct{})
+	go func() {
+		defer close(runnerDone)
+		importRunner.Run(ctx, cfg.Imports.Workers, cfg.ShutdownTimeout)
+	}()
+	// Deferred calls run in reverse, so the runner is waited for before
+	// the database and the geocoder close.
+	defer func() {
+		stop()
+		<-runnerDone
+	}()
+	controlImportUsecase := usecase.NewControlImportUseCase(importJobRepo, orderRepo, uploads, importRunner, logger)
+	watchImportUsecase := usecase.NewWatchImportUseCase(importJobRepo, importHub, cfg.Imports.PollInterval)
+
+	readinessChecks := repos.checks
+	// The geocoder probe goes through the governor and spends quota like
+	// any lookup, so its result is reused between probes, and an outage
+	// only degrades the service: orders for points already cached can
+	// still be taxed.
+	if cfg.Readiness.CheckGeocoder {
+		readinessChecks = append(readinessChecks, usecase.HealthCheck{
+			Name: "geocoder", CacheFor: cfg.Readiness.GeocoderEvery, Check: geocoderApi.Ping,
+		})
+	}
+	readinessUsecase := usecase.NewCheckReadinessUseCase(cfg.Readiness.Timeout, readinessChecks...)
+
+	importController := controller.NewImportController(enqueueImportUsecase)
+	getImportController := controller.NewGetImportController(getImportUsecase)
+	controlImportController := controller.NewControlImportController(controlImportUsecase)
+	importEventsController := controller.NewImportEventsController(watchImportUsecase, ctx.Done())
+	createController := controller.NewCreateController(createUsecase)
+	quoteController := controller.NewQuoteController(quoteUsecase)
+	getController := controller.NewGetController(listUsecase, location)
+	exportController := controller.NewExportController(exportUsecase, location)
+	timeseriesController := controller.NewTimeseriesController(timeseriesUsecase, location)
+	heatmapController := controller.NewHeatmapController(heatmapUsecase, location)
+	taxSettingsController := controller.NewTaxSettingsController(taxSettingsUsecase)
+	healthController := controller.NewHealthController()
+	livenessController := controller.NewLivenessController()
+	readinessController := controller.NewReadinessController(readinessUsecase)
+
+	auth := controller.NewAuthMiddleware(authUsecase, cfg.Auth.Enabled)
+	if !cfg.Auth.Enabled {
+		logger.Warn("Authentication is disabled; set AUTH_ENABLED=true to require credentials")
+	}
+
+	limits := cfg.Limits
+	createLimiter := controller.NewRateLimiter("create", limits.CreatePerMinute, limits.CreateBurst, limits.TrustProxy)
+	quoteLimiter := controller.NewRateLimiter("quote", limits.QuotePerMinute, limits.QuoteBurst, limits.TrustProxy)
+	importLimiter := controller.NewRateLimiter("import", limits.ImportPerMinute, limits.ImportBurst, limits.TrustProxy)
+
+	router.Handle("POST /orders/import", auth.Require(
+		importLimiter.Limit(controller.LimitBody(importController, cfg.Imports.MaxUploadBytes)), entity.RoleImporter))
+	router.Handle("GET /orders/import/{id}", auth.Require(getImportController,
+		entity.RoleImporter, entity.RoleAnalyst))
+	router.Handle("GET /orders/import/{id}/events", auth.Require(importEventsController,
+		entity.RoleImporter, entity.RoleAnalyst))
+	router.Handle("POST /orders/import/{id}/{action}", auth.Require(controlImportController,
+		entity.RoleImporter))
+	router.Handle("POST /orders", auth.Require(
+		createLimiter.Limit(controller.LimitBody(createController, limits.MaxJSONBodyBytes)), entity.RoleOrderWriter))
+	router.Handle("POST /orders/quote", auth.Require(
+		quoteLimiter.Limit(controller.LimitBody(quoteController, limits.MaxJSONBodyBytes)),
+		entity.RoleOrderWriter, entity.RoleAnalyst))
+	router.Handle("GET /orders", auth.Require(getController, entity.RoleAnalyst))
+	router.Handle("GET /orders/export", auth.Require(exportController, entity.RoleAnalyst))
+	router.Handle("GET /analytics/timeseries", auth.Require(timeseriesController, entity.RoleAnalyst))
+	router.Handle("GET /analytics/heatmap", auth.Require(heatmapController, entity.RoleAnalyst))
+	router.Handle("GET /tax-settings", auth.Require(taxSettingsController, entity.RoleRateAdmin))
+	router.Handle("PUT /tax-settings", auth.Require(
+		controller.LimitBody(taxSettingsController, limits.MaxJSONBodyBytes), entity.RoleRateAdmin))
+	router.Handle("GET /health", healthController)
+	router.Handle("GET /livez", livenessController)
+	router.Handle("GET /readyz", readinessController)
+	router.Handle("GET /metrics", metrics.Handler())
+
+	// Credentials are only allowed together with an explicit origin list;
+	// browsers reject a wildcard origin on credentialed requests anyway.
+	allowCredentials := !slices.Contains(cfg.CORSOrigins, "*")
+
+	c := cors.New(cors.Options{
+		AllowedOrigins: cfg.CORSOrigins,
+		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Authorization", "Content-Type", "X-API-Key", "X-Request-Id"},
+		ExposedHeaders: []string{"RateLimit-Limit", "RateLimit-Remaining",
+			"RateLimit-Reset", "RateLimit-Policy", "Retry-After", "X-Request-Id"},
+		AllowCredentials: allowCredentials,
+		Debug:            cfg.Env != "PROD",
+		Logger:           slog.NewLogLogger(logger.Handler(), slog.LevelDebug),
+	})
+
+	// Instrument and the tracing middleware wrap the router without
+	// copying the request in between, so they see the route it matched.
+	handler := controller.RequestId(otelhttp.NewHandler(controller.Instrument(c.Handler(router)), "http",
+		otelhttp.WithSpanNameFormatter(controller.SpanName)))
+
+	server := &http.Server{
+		Addr:         ":" + cfg.Port,
+		WriteTimeout: writeTimeout,
+		ReadTimeout:  readTimeout,
+		Handler:      handler,
+	}
+
+	logger.Info("Listening", "port", cfg.Port)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+	logger.Info("Shutting down, draining requests and imports")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Shutdown waits for in-flight requests, import event streams having
+	// ended with ctx; requests that outlast the deadline are cut off.
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("Shutdown deadline passed, closing remaining connections", "error", err)
+		if err := server.Close(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"InstantWellnessKits/src/config"
+	"InstantWellnessKits/src/metrics"
+	"InstantWellnessKits/src/repository/memory"
+	"InstantWellnessKits/src/repository/postgres"
+	api_key "InstantWellnessKits/src/repository/postgres/api-key"
+	import_job "InstantWellnessKits/src/repository/postgres/import-job"
+	"InstantWellnessKits/src/repository/postgres/order"
+	tax_rate "InstantWellnessKits/src/repository/postgres/tax-rate"
+	"InstantWellnessKits/src/repository/sqlite"
+	"InstantWellnessKits/src/usecase"
+	"context"
+	"database/sql"
+	"log/slog"
+)
+
+type taxRateRepository interface {
+	usecase.TaxRates
+	usecase.TaxSettings
+}
+
+// repositories holds the storage selected by STORAGE and the readiness
+// checks that cover it.
+type repositories struct {
+	orders     usecase.Orders
+	taxRates   taxRateRepository
+	apiKeys    usecase.APIKeys
+	importJobs usecase.ImportJobs
+	checks     []usecase.HealthCheck
+	close      func()
+}
+
+// openRepositories opens the storage selected by STORAGE; the returned
+// repositories must be closed. SQLite holds import jobs in memory, and the
+// memory backend holds everything there.
+func openRepositories(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*repositories, error) {
+	switch cfg.Storage {
+	case config.StorageMemory:
+		taxRates, err := memory.NewTaxRates()
+		if err != nil {
+			return nil, err
+		}
+		logger.Warn("Using in-memory storage; all data is lost on exit")
+		return &repositories{
+			orders:     memory.NewOrders(),
+			taxRates:   taxRates,
+			apiKeys:    memory.NewAPIKeys(taxRates),
+			importJobs: memory.NewImportJobs(),
+			close:      func() {},
+		}, nil
+
+	case config.StorageSQLite:
+		conn, err := sqlite.InitDb(ctx, cfg.SQLitePath)
+		if err != nil {
+			return nil, err
+		}
+		closeDb := func() {
+			if err := conn.Close(); err != nil {
+				logger.Error("Failed to close database", "error", err)
+			}
+		}
+		if err := metrics.RegisterDB(conn, "sqlite"); err != nil {
+			closeDb()
+			return nil, err
+		}
+
+		taxRates := sqlite.NewTaxRates(conn)
+		logger.Info("Using SQLite storage", "path", cfg.SQLitePath)
+		return &repositories{
+			orders:     sqlite.NewOrders(conn),
+			taxRates:   taxRates,
+			apiKeys:    sqlite.NewAPIKeys(conn),
+			importJobs: memory.NewImportJobs(),
+			checks: []usecase.HealthCheck{
+				{Name: "database", Critical: true, Check: conn.PingContext},
+			},
+			close: closeDb,
+		}, nil
+	}
+
+	conn, closeDb, err := openDb(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := postgresRepositories(ctx, cfg, conn)
+	if err != nil {
+		closeDb()
+		return nil, err
+	}
+	repos.close = closeDb
+	return repos, nil
+}
+
+func postgresRepositories(ctx context.Context, cfg *config.Config, conn *sql.DB) (*repositories, error) {
+	if err := metrics.RegisterDB(conn, cfg.Database.Name); err != nil {
+		return nil, err
+	}
+
+	// Instances starting together take turns migrating under an advisory
+	// lock. Without AUTO_MIGRATE the schema is migrated separately, e.g.
+	// by a `migrate up` job, and an instance refuses to start on a schema
+	// it was not built for.
+	if cfg.AutoMigrate {
+		if err := postgres.ApplyMigrations(ctx, conn); err != nil {
+			return nil, err
+		}
+		if err := postgres.SeedTaxRates(ctx, conn); err != nil {
+			return nil, err
+		}
+	} else if err := postgres.CheckSchema(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	dbHealth, err := postgres.NewHealth(conn)
+	if err != nil {
+		return nil, err
+	}
+	return &repositories{
+		orders:     order.NewRepository(conn),
+		taxRates:   tax_rate.NewRepository(conn),
+		apiKeys:    api_key.NewRepository(conn),
+		importJobs: import_job.NewRepository(conn),
+		checks: []usecase.HealthCheck{
+			{Name: "database", Critical: true, Check: dbHealth.Ping},
+			{Name: "migrations", Critical: true, Check: dbHealth.CheckMigrations},
+			{Name: "tax_rates", Critical: true, Check: dbHealth.CheckTaxRates},
+		},
+	}, nil
+}
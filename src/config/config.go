@@ -1,25 +1,96 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
 	"github.com/caarlos0/env/v6"
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Port            string `env:"PORT" envDefault:"8080"`
+	Port string `env:"PORT" envDefault:"8080"`
+	// ShutdownTimeout bounds how long in-flight requests and imports get
+	// to finish after SIGTERM; keep it under the platform's grace period.
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"8s"`
+	// AutoMigrate applies migrations and seeds tax rates on startup.
+	AutoMigrate bool `env:"AUTO_MIGRATE" envDefault:"false"`
+	// Storage selects where orders and tax rates live: postgres, or memory
+	// and sqlite for running on a laptop.
+	Storage         string `env:"STORAGE" envDefault:"postgres"`
+	SQLitePath      string `env:"SQLITE_PATH" envDefault:"data/iwk.db"`
 	GeocodingAPIKey string `env:"GEOCODING_API_KEY,required"`
-	Database        struct {
-		Name           string `env:"DB_NAME,required"`
+	Geocoding       struct {
+		PerSecond float64 `env:"GEOCODING_QPS" envDefault:"20"`
+		DailyCap  int     `env:"GEOCODING_DAILY_CAP" envDefault:"0"`
+		QuotaZone string  `env:"GEOCODING_QUOTA_TIMEZONE" envDefault:"America/Los_Angeles"`
+	}
+	Database struct {
+		Name           string `env:"DB_NAME"`
 		Password       string `env:"DB_PASSWORD"`
-		User           string `env:"DB_USER,required"`
-		UserPassword   string `env:"DB_USER_PASSWORD,required"`
+		User           string `env:"DB_USER"`
+		UserPassword   string `env:"DB_USER_PASSWORD"`
 		ConnectionName string `env:"INSTANCE_CONNECTION_NAME"`
 		Host           string `env:"DB_HOST"`
 		Port           string `env:"DB_PORT"`
 	}
-	Env string `env:"ENV" envDefault:"DEV"`
+	Log struct {
+		Level  string `env:"LOG_LEVEL" envDefault:"info"`
+		Format string `env:"LOG_FORMAT" envDefault:"text"`
+	}
+	Tracing struct {
+		Exporter     string  `env:"TRACING_EXPORTER" envDefault:"none"`
+		OTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT"`
+		SampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+	}
+	Readiness struct {
+		Timeout       time.Duration `env:"READINESS_TIMEOUT" envDefault:"2s"`
+		CheckGeocoder bool          `env:"READINESS_CHECK_GEOCODER" envDefault:"false"`
+		GeocoderEvery time.Duration `env:"READINESS_GEOCODER_INTERVAL" envDefault:"5m"`
+	}
+	Env              string   `env:"ENV" envDefault:"DEV"`
+	BusinessTimezone string   `env:"BUSINESS_TIMEZONE" envDefault:"America/New_York"`
+	CORSOrigins      []string `env:"CORS_ALLOWED_ORIGINS" envSeparator:"," envDefault:"*"`
+	Auth             struct {
+		// Enabled defaults to false for STORAGE=memory, see New.
+		Enabled       bool   `env:"AUTH_ENABLED" envDefault:"true"`
+		JWTSigningKey string `env:"JWT_SIGNING_KEY"`
+		JWTIssuer     string `env:"JWT_ISSUER"`
+	}
+	Imports struct {
+		StorageDir     string        `env:"IMPORT_STORAGE_DIR" envDefault:"data/imports"`
+		MaxUploadBytes int64         `env:"IMPORT_MAX_UPLOAD_BYTES" envDefault:"2147483648"`
+		Workers        int           `env:"IMPORT_WORKERS" envDefault:"1"`
+		PollInterval   time.Duration `env:"IMPORT_POLL_INTERVAL" envDefault:"2s"`
+		Lease          time.Duration `env:"IMPORT_LEASE" envDefault:"2m"`
+	}
+	Limits struct {
+		TrustProxy       bool    `env:"TRUST_PROXY_HEADERS" envDefault:"false"`
+		CreatePerMinute  float64 `env:"RATE_LIMIT_CREATE_PER_MINUTE" envDefault:"60"`
+		CreateBurst      int     `env:"RATE_LIMIT_CREATE_BURST" envDefault:"10"`
+		QuotePerMinute   float64 `env:"RATE_LIMIT_QUOTE_PER_MINUTE" envDefault:"120"`
+		QuoteBurst       int     `env:"RATE_LIMIT_QUOTE_BURST" envDefault:"20"`
+		ImportPerMinute  float64 `env:"RATE_LIMIT_IMPORT_PER_MINUTE" envDefault:"2"`
+		ImportBurst      int     `env:"RATE_LIMIT_IMPORT_BURST" envDefault:"2"`
+		MaxJSONBodyBytes int64   `env:"MAX_JSON_BODY_BYTES" envDefault:"65536"`
+	}
 }
 
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+	StorageSQLite   = "sqlite"
+)
+
+var (
+	ErrUnknownStorage   = errors.New(`STORAGE must be postgres, memory or sqlite`)
+	ErrInvalidRateLimit = errors.New(`rate limits need a positive number of requests per minute and a burst of at least 1`)
+	ErrInvalidQPS       = errors.New(`GEOCODING_QPS must be a positive number`)
+)
+
 func New() (*Config, error) {
 	_ = godotenv.Load(".env")
 
@@ -27,5 +98,45 @@ func New() (*Config, error) {
 	if err := env.Parse(cfg); err != nil {
 		return nil, err
 	}
+
+	switch cfg.Storage {
+	case StoragePostgres:
+		for name, value := range map[string]string{
+			"DB_NAME":          cfg.Database.Name,
+			"DB_USER":          cfg.Database.User,
+			"DB_USER_PASSWORD": cfg.Database.UserPassword,
+		} {
+			if value == "" {
+				return nil, fmt.Errorf(`required environment variable %q is not set`, name)
+			}
+		}
+	case StorageMemory:
+		// A key issued by `main apikey` would die with that process, so
+		// only JWTs could authenticate; the default follows suit.
+		if _, set := os.LookupEnv("AUTH_ENABLED"); !set {
+			cfg.Auth.Enabled = false
+		}
+	case StorageSQLite:
+	default:
+		return nil, fmt.Errorf("%w, got %q", ErrUnknownStorage, cfg.Storage)
+	}
+
+	if !(cfg.Geocoding.PerSecond > 0) || math.IsInf(cfg.Geocoding.PerSecond, 1) {
+		return nil, fmt.Errorf("%w, got %v", ErrInvalidQPS, cfg.Geocoding.PerSecond)
+	}
+	for _, limit := range []struct {
+		name      string
+		perMinute float64
+		burst     int
+	}{
+		{"CREATE", cfg.Limits.CreatePerMinute, cfg.Limits.CreateBurst},
+		{"QUOTE", cfg.Limits.QuotePerMinute, cfg.Limits.QuoteBurst},
+		{"IMPORT", cfg.Limits.ImportPerMinute, cfg.Limits.ImportBurst},
+	} {
+		if !(limit.perMinute > 0) || math.IsInf(limit.perMinute, 1) || limit.burst < 1 {
+			return nil, fmt.Errorf("%w, got RATE_LIMIT_%s_PER_MINUTE=%v and RATE_LIMIT_%s_BURST=%d",
+				ErrInvalidRateLimit, limit.name, limit.perMinute, limit.name, limit.burst)
+		}
+	}
 	return cfg, nil
 }
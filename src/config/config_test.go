@@ -0,0 +1,47 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewRejectsInvalidRateLimits(t *testing.T) {
+	tests := []struct {
+		name, variable, value string
+	}{
+		{"zero per minute", "RATE_LIMIT_CREATE_PER_MINUTE", "0"},
+		{"negative per minute", "RATE_LIMIT_QUOTE_PER_MINUTE", "-5"},
+		{"NaN per minute", "RATE_LIMIT_IMPORT_PER_MINUTE", "NaN"},
+		{"infinite per minute", "RATE_LIMIT_CREATE_PER_MINUTE", "+Inf"},
+		{"zero burst", "RATE_LIMIT_QUOTE_BURST", "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STORAGE", StorageMemory)
+			t.Setenv("GEOCODING_API_KEY", "test")
+			t.Setenv(tt.variable, tt.value)
+			if _, err := New(); !errors.Is(err, ErrInvalidRateLimit) {
+				t.Errorf("%s=%s: err = %v, want %v", tt.variable, tt.value, err, ErrInvalidRateLimit)
+			}
+		})
+	}
+
+	t.Setenv("STORAGE", StorageMemory)
+	t.Setenv("GEOCODING_API_KEY", "test")
+	if _, err := New(); err != nil {
+		t.Errorf("defaults: %v", err)
+	}
+}
+
+func TestNewRejectsInvalidGeocodingQPS(t *testing.T) {
+	for _, value := range []string{"0", "-1", "NaN", "+Inf"} {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("STORAGE", StorageMemory)
+			t.Setenv("GEOCODING_API_KEY", "test")
+			t.Setenv("GEOCODING_QPS", value)
+			if _, err := New(); !errors.Is(err, ErrInvalidQPS) {
+				t.Errorf("GEOCODING_QPS=%s: err = %v, want %v", value, err, ErrInvalidQPS)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	codeUnauthenticated = "unauthenticated"
+	codeForbidden       = "forbidden"
+)
+
+// AuthMiddleware authenticates requests with an API key or JWT and enforces
+// per-route roles.
+type AuthMiddleware struct {
+	uc      *usecase.AuthenticateUseCase
+	enabled bool
+}
+
+func NewAuthMiddleware(uc *usecase.AuthenticateUseCase, enabled bool) *AuthMiddleware {
+	return &AuthMiddleware{
+		uc:      uc,
+		enabled: enabled,
+	}
+}
+
+// Require wraps next so that it only runs for callers holding one of roles.
+func (m *AuthMiddleware) Require(next http.Handler, roles ...entity.Role) http.Handler {
+	if !m.enabled {
+		return next
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		principal, err := m.uc.Execute(r.Context(), credentials(r))
+		if err != nil {
+			writeAuthError(rw, r, err)
+			return
+		}
+
+		if err := usecase.Authorize(principal, roles...); err != nil {
+			writeAuthError(rw, r, err)
+			return
+		}
+
+		next.ServeHTTP(rw, r.WithContext(entity.ContextWithPrincipal(r.Context(), principal)))
+	})
+}
+
+func credentials(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+func writeAuthError(rw http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrUnauthenticated):
+		rw.Header().Set("WWW-Authenticate", `Bearer realm="instant-wellness-kits"`)
+		writeProblem(rw, r, http.StatusUnauthorized, codeUnauthenticated,
+			usecase.ErrUnauthenticated.Error())
+	case errors.Is(err, usecase.ErrForbidden):
+		writeProblem(rw, r, http.StatusForbidden, codeForbidden, err.Error())
+	default:
+		writeError(rw, r, err)
+	}
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/repository/memory"
+	"InstantWellnessKits/src/usecase"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	rates, err := memory.NewTaxRates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := memory.NewAPIKeys(rates)
+	analystKey, _, err := usecase.NewManageAPIKeysUseCase(keys).Issue(context.Background(),
+		"dashboard", "brand-b", []string{"analyst"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := usecase.NewAuthenticateUseCase(keys, "", "")
+
+	var seen *entity.Principal
+	ok := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		seen, _ = entity.PrincipalFromContext(r.Context())
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name    string
+		enabled bool
+		header  string
+		value   string
+		role    entity.Role
+		status  int
+	}{
+		{"X-API-Key", true, "X-API-Key", analystKey, entity.RoleAnalyst, http.StatusNoContent},
+		{"bearer", true, "Authorization", "bearer " + analystKey, entity.RoleAnalyst, http.StatusNoContent},
+		{"other scheme", true, "Authorization", "Basic " + analystKey, entity.RoleAnalyst, http.StatusUnauthorized},
+		{"missing", true, "", "", entity.RoleAnalyst, http.StatusUnauthorized},
+		{"unknown key", true, "X-API-Key", "iwk_nope", entity.RoleAnalyst, http.StatusUnauthorized},
+		{"wrong role", true, "X-API-Key", analystKey, entity.RoleRateAdmin, http.StatusForbidden},
+		{"disabled", false, "", "", entity.RoleRateAdmin, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = nil
+			r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			NewAuthMiddleware(uc, tt.enabled).Require(ok, tt.role).ServeHTTP(rec, r)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			switch {
+			case tt.status == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "":
+				t.Error("401 without WWW-Authenticate")
+			case tt.status != http.StatusNoContent && rec.Header().Get("Content-Type") != problemContentType:
+				t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), problemContentType)
+			case tt.status == http.StatusNoContent && tt.enabled && (seen == nil || seen.TenantId != "brand-b"):
+				t.Errorf("handler saw principal %+v, want one of tenant brand-b", seen)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const codePayloadTooLarge = "payload_too_large"
+
+// LimitBody rejects request bodies larger than limit bytes.
+func LimitBody(next http.Handler, limit int64) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			writeProblem(rw, r, http.StatusRequestEntityTooLarge, codePayloadTooLarge,
+				fmt.Sprintf("Request body must not exceed %d bytes.", limit))
+			return
+		}
+		r.Body = http.MaxBytesReader(rw, r.Body, limit)
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// decodeJSON decodes the request body into v, writing the problem response
+// itself and returning false when the body is oversized or malformed.
+func decodeJSON(rw http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeProblem(rw, r, http.StatusRequestEntityTooLarge, codePayloadTooLarge,
+			fmt.Sprintf("Request body must not exceed %d bytes.", maxBytesErr.Limit))
+		return false
+	}
+
+	writeProblem(rw, r, http.StatusBadRequest, codeMalformedBody, err.Error())
+	return false
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// ControlImportController handles POST /orders/import/{id}/{action} for the
+// pause, resume and cancel actions. Cancel takes an optional
+// ?rollback=true to delete the orders the job already inserted.
+type ControlImportController struct {
+	uc *usecase.ControlImportUseCase
+}
+
+func NewControlImportController(uc *usecase.ControlImportUseCase) *ControlImportController {
+	return &ControlImportController{
+		uc: uc,
+	}
+}
+
+func (h *ControlImportController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(rw, r, usecase.InvalidField("id", errInvalidId))
+		return
+	}
+
+	var job *entity.ImportJob
+	switch r.PathValue("action") {
+	case "pause":
+		job, err = h.uc.Pause(r.Context(), id)
+	case "resume":
+		job, err = h.uc.Resume(r.Context(), id)
+	case "cancel":
+		rollback := false
+		if raw := r.URL.Query().Get("rollback"); raw != "" {
+			rollback, err = strconv.ParseBool(raw)
+			if err != nil {
+				writeError(rw, r, usecase.InvalidField("rollback", errInvalidBool))
+				return
+			}
+		}
+		job, err = h.uc.Cancel(r.Context(), id, rollback)
+	default:
+		writeProblem(rw, r, http.StatusNotFound, codeNotFound, "Unknown import action.")
+		return
+	}
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		writeError(rw, r, fmt.Errorf("encoding import job: %w", err))
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	_, err = rw.Write(encoded)
+	if err != nil {
+		return
+	}
+}
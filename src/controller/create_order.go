@@ -3,8 +3,7 @@ package controller
 import (
 	"InstantWellnessKits/src/usecase"
 	"encoding/json"
-	"errors"
-	"log"
+	"fmt"
 	"net/http"
 )
 
@@ -27,40 +26,50 @@ func NewCreateController(uc *usecase.CreateOrderUseCase) *CreateController {
 
 func (h *CreateController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	var body createOrderRequest
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(rw, err.Error(), http.StatusBadRequest)
+	if !decodeJSON(rw, r, &body) {
 		return
 	}
 
-	if body.Latitude == nil || body.Longitude == nil || body.Subtotal == nil || body.Timestamp == nil {
-		http.Error(rw, "Missing required fields", http.StatusBadRequest)
+	if err := body.validate(); err != nil {
+		writeError(rw, r, err)
 		return
 	}
 
 	order, err := h.uc.Execute(r.Context(), *body.Latitude, *body.Longitude,
 		*body.Subtotal, *body.Timestamp)
 	if err != nil {
-		if errors.Is(err, usecase.ErrFailedParsingTimestamp) {
-			http.Error(rw, "Invalid timestamp format", http.StatusBadRequest)
-			return
-		}
-		http.Error(rw, "Failed to create order", http.StatusInternalServerError)
-		log.Println("Error executing use case:", err)
+		writeError(rw, r, err)
 		return
 	}
 
 	encodedOrder, err := json.Marshal(order)
 	if err != nil {
-		http.Error(rw, "Failed to encode order", http.StatusInternalServerError)
-		log.Println("Error encoding order:", err)
+		writeError(rw, r, fmt.Errorf("encoding order: %w", err))
 		return
 	}
 
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusAccepted)
+
 	_, err = rw.Write(encodedOrder)
 	if err != nil {
 		return
 	}
+}
 
-	rw.Header().Set("Content-Type", "application/json")
-	rw.WriteHeader(http.StatusAccepted)
+func (b createOrderRequest) validate() error {
+	verr := &usecase.ValidationError{}
+	if b.Latitude == nil {
+		verr.Add("latitude", usecase.ErrMissingField)
+	}
+	if b.Longitude == nil {
+		verr.Add("longitude", usecase.ErrMissingField)
+	}
+	if b.Subtotal == nil {
+		verr.Add("subtotal", usecase.ErrMissingField)
+	}
+	if b.Timestamp == nil {
+		verr.Add("timestamp", usecase.ErrMissingField)
+	}
+	return verr.OrNil()
 }
@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+
+	exportFlushEvery = 1000
+)
+
+var exportColumns = []string{
+	"id", "latitude", "longitude", "subtotal", "compositeTaxRate",
+	"taxAmount", "totalAmount", "stateRate", "countyRate", "cityRate",
+	"specialRate", "state", "county", "city", "special", "timestamp",
+}
+
+// exportRow is the flattened representation of an order, with the tax
+// breakdown and jurisdiction lifted into top-level columns.
+type exportRow struct {
+	Id               string `json:"id"`
+	Latitude         string `json:"latitude"`
+	Longitude        string `json:"longitude"`
+	Subtotal         string `json:"subtotal"`
+	CompositeTaxRate string `json:"compositeTaxRate"`
+	TaxAmount        string `json:"taxAmount"`
+	TotalAmount      string `json:"totalAmount"`
+	StateRate        string `json:"stateRate"`
+	CountyRate       string `json:"countyRate"`
+	CityRate         string `json:"cityRate"`
+	SpecialRate      string `json:"specialRate"`
+	State            string `json:"state"`
+	County           string `json:"county"`
+	City             string `json:"city"`
+	Special          string `json:"special"`
+	Timestamp        string `json:"timestamp"`
+}
+
+func newExportRow(order *entity.Order, loc *time.Location) exportRow {
+	return exportRow{
+		Id:               order.Id.String(),
+		Latitude:         strconv.FormatFloat(order.Latitude, 'f', -1, 64),
+		Longitude:        strconv.FormatFloat(order.Longitude, 'f', -1, 64),
+		Subtotal:         order.Subtotal.String(),
+		CompositeTaxRate: order.CompositeTaxRate.String(),
+		TaxAmount:        order.TaxAmount.String(),
+		TotalAmount:      order.TotalAmount.String(),
+		StateRate:        order.Breakdown.StateRate.String(),
+		CountyRate:       order.Breakdown.CountyRate.String(),
+		CityRate:         order.Breakdown.CityRate.String(),
+		SpecialRate:      order.Breakdown.SpecialRate.String(),
+		State:            order.Jurisdiction.State,
+		County:           order.Jurisdiction.County,
+		City:             order.Jurisdiction.City,
+		Special:          order.Jurisdiction.Special,
+		Timestamp:        order.Timestamp.In(loc).Format(time.RFC3339Nano),
+	}
+}
+
+func (e exportRow) record() []string {
+	return []string{
+		e.Id, e.Latitude, e.Longitude, e.Subtotal, e.CompositeTaxRate,
+		e.TaxAmount, e.TotalAmount, e.StateRate, e.CountyRate, e.CityRate,
+		e.SpecialRate, e.State, e.County, e.City, e.Special, e.Timestamp,
+	}
+}
+
+type ExportController struct {
+	uc       *usecase.ExportOrdersUseCase
+	location *time.Location
+}
+
+func NewExportController(uc *usecase.ExportOrdersUseCase, location *time.Location) *ExportController {
+	return &ExportController{
+		uc:       uc,
+		location: location,
+	}
+}
+
+func (h *ExportController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(r)
+	if !ok {
+		writeProblem(rw, r, http.StatusNotAcceptable, codeUnsupportedFormat,
+			"Supported export formats are csv and ndjson.")
+		return
+	}
+
+	params, err := parseListParams(r.URL.Query(), h.location)
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+
+	// Exports can run far longer than the server-wide write timeout.
+	rc := http.NewResponseController(rw)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	buffered := bufio.NewWriter(rw)
+
+	var write func(*entity.Order) error
+	var finish func() error
+
+	switch format {
+	case exportFormatNDJSON:
+		rw.Header().Set("Content-Type", "application/x-ndjson")
+		rw.Header().Set("Content-Disposition", `attachment; filename="orders.ndjson"`)
+
+		encoder := json.NewEncoder(buffered)
+		write = func(order *entity.Order) error {
+			return encoder.Encode(newExportRow(order, h.location))
+		}
+		finish = buffered.Flush
+	default:
+		rw.Header().Set("Content-Type", "text/csv")
+		rw.Header().Set("Content-Disposition", `attachment; filename="orders.csv"`)
+
+		writer := csv.NewWriter(buffered)
+		if err := writer.Write(exportColumns); err != nil {
+			return
+		}
+		write = func(order *entity.Order) error {
+			return writer.Write(newExportRow(order, h.location).record())
+		}
+		finish = func() error {
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+			return buffered.Flush()
+		}
+	}
+
+	rw.WriteHeader(http.StatusOK)
+
+	written := 0
+	err = h.uc.Execute(r.Context(), params, func(order *entity.Order) error {
+		if err := write(order); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			if err := finish(); err != nil {
+				return err
+			}
+			_ = rc.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// Headers are already sent, so the client only sees a truncated body.
+		slog.ErrorContext(r.Context(), "Error exporting orders", "error", err)
+		return
+	}
+
+	if err := finish(); err != nil {
+		slog.ErrorContext(r.Context(), "Error flushing export", "error", err)
+	}
+}
+
+func exportFormat(r *http.Request) (string, bool) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		switch strings.ToLower(format) {
+		case exportFormatCSV:
+			return exportFormatCSV, true
+		case exportFormatNDJSON, "jsonl":
+			return exportFormatNDJSON, true
+		}
+		return "", false
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "application/x-ndjson"),
+		strings.Contains(accept, "application/ndjson"),
+		strings.Contains(accept, "application/jsonl"):
+		return exportFormatNDJSON, true
+	default:
+		return exportFormatCSV, true
+	}
+}
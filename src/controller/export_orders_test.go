@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/repository/memory"
+	"InstantWellnessKits/src/usecase"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// exportFixture stores n orders in Kings County, one a minute from noon
+// on 10 March 2025 in New York, plus one in Albany and one of another
+// tenant. The special district of every Kings order needs CSV quoting.
+func exportFixture(t *testing.T, n int) (*ExportController, []*entity.Order) {
+	t.Helper()
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, loc)
+
+	newOrder := func(tenantId, county, special string, at time.Time) *entity.Order {
+		order := entity.NewOrder(40.65, -73.95, decimal.RequireFromString("10.00"),
+			decimal.RequireFromString("0.08875"), decimal.RequireFromString("0.89"),
+			decimal.RequireFromString("10.89"),
+			entity.NewTaxBreakdown(decimal.RequireFromString("0.04"), decimal.RequireFromString("0.045"),
+				decimal.Zero, decimal.RequireFromString("0.00375")),
+			entity.NewJurisdiction("New York", county, "New York City", special), at)
+		order.TenantId = tenantId
+		return order
+	}
+
+	kings := make([]*entity.Order, n)
+	for i := range kings {
+		kings[i] = newOrder(entity.DefaultTenant, "Kings", `Metropolitan "MCTD", zone 1`,
+			start.Add(time.Duration(i)*time.Minute))
+	}
+	orders := memory.NewOrders()
+	ctx := context.Background()
+	for _, batch := range [][]*entity.Order{
+		kings,
+		{newOrder(entity.DefaultTenant, "Albany", "", start)},
+		{newOrder("brand-b", "Kings", "", start)},
+	} {
+		if err := orders.CreateBatch(ctx, batch); err != nil {
+			t.Fatalf("CreateBatch: %v", err)
+		}
+	}
+	return NewExportController(usecase.NewExportOrdersUseCase(orders), loc), kings
+}
+
+func export(h http.Handler, target string, configure func(*http.Request)) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	if configure != nil {
+		configure(r)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestExportCSV(t *testing.T) {
+	// More rows than one flush, so the export is written in several parts.
+	h, kings := exportFixture(t, 2*exportFlushEvery+5)
+
+	rec := export(h, "/orders/export?county=Kings", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("status = %d, Content-Type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("reading the export: %v", err)
+	}
+	if !slices.Equal(records[0], exportColumns) {
+		t.Errorf("header = %v, want %v", records[0], exportColumns)
+	}
+
+	exported := records[1:]
+	if len(exported) != len(kings) {
+		t.Fatalf("exported %d orders, want the %d in Kings County of this tenant", len(exported), len(kings))
+	}
+	seen := make(map[string]bool, len(exported))
+	for _, record := range exported {
+		seen[record[0]] = true
+		if record[12] != "Kings" || record[14] != `Metropolitan "MCTD", zone 1` {
+			t.Fatalf("record = %v, want Kings with its special district intact", record)
+		}
+	}
+	for _, order := range kings {
+		if !seen[order.Id.String()] {
+			t.Fatalf("order %s is missing from the export", order.Id)
+		}
+	}
+
+	// The breakdown and jurisdiction are flattened, and timestamps are
+	// written in the business timezone.
+	first := exported[len(exported)-1]
+	want := []string{"40.65", "-73.95", "10", "0.08875", "0.89", "10.89", "0.04", "0.045", "0", "0.00375",
+		"New York", "Kings", "New York City", `Metropolitan "MCTD", zone 1`, "2025-03-10T12:00:00-04:00"}
+	if !slices.Equal(first[1:], want) {
+		t.Errorf("oldest record = %v, want %v", first[1:], want)
+	}
+}
+
+func TestExportNDJSON(t *testing.T) {
+	h, kings := exportFixture(t, 3)
+
+	for _, tt := range []struct {
+		name      string
+		target    string
+		configure func(*http.Request)
+	}{
+		{"format parameter", "/orders/export?format=ndjson&county=Kings", nil},
+		{"accept header", "/orders/export?county=Kings", func(r *http.Request) {
+			r.Header.Set("Accept", "application/x-ndjson")
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := export(h, tt.target, tt.configure)
+			if rec.Header().Get("Content-Type") != "application/x-ndjson" {
+				t.Fatalf("Content-Type = %q", rec.Header().Get("Content-Type"))
+			}
+			var rows []exportRow
+			lines := bufio.NewScanner(rec.Body)
+			for lines.Scan() {
+				var row exportRow
+				if err := json.Unmarshal(lines.Bytes(), &row); err != nil {
+					t.Fatalf("line %q: %v", lines.Text(), err)
+				}
+				rows = append(rows, row)
+			}
+			if len(rows) != len(kings) || rows[0].Special != `Metropolitan "MCTD", zone 1` {
+				t.Errorf("rows = %+v, want the %d orders in Kings County", rows, len(kings))
+			}
+		})
+	}
+}
+
+func TestExportFilters(t *testing.T) {
+	h, _ := exportFixture(t, 90)
+
+	tests := []struct {
+		name   string
+		target string
+		rows   int
+	}{
+		{"everything of the tenant", "/orders/export", 91},
+		{"county", "/orders/export?county=Albany", 1},
+		// Minutes 0 to 30 past noon, New York time.
+		{"time range", "/orders/export?county=Kings&from=2025-03-10T12:00:00-04:00&to=2025-03-10T12:30:00-04:00", 31},
+		{"whole local day", "/orders/export?county=Kings&from=2025-03-10&to=2025-03-10", 90},
+		{"other day", "/orders/export?from=2025-03-11", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := export(h, tt.target, nil)
+			records, err := csv.NewReader(rec.Body).ReadAll()
+			if err != nil {
+				t.Fatalf("reading the export: %v", err)
+			}
+			if got := len(records) - 1; got != tt.rows {
+				t.Errorf("exported %d orders, want %d", got, tt.rows)
+			}
+		})
+	}
+
+	for _, target := range []string{"/orders/export?format=xml", "/orders/export?from=yesterday"} {
+		if rec := export(h, target, nil); rec.Code < 400 || rec.Header().Get("Content-Type") != problemContentType {
+			t.Errorf("GET %s: status = %d, Content-Type = %q, want a problem", target, rec.Code,
+				rec.Header().Get("Content-Type"))
+		}
+	}
+}
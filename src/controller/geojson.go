@@ -0,0 +1,51 @@
+package controller
+
+// Minimal GeoJSON (RFC 7946) types used by the map-oriented endpoints.
+
+const geoJSONContentType = "application/geo+json"
+
+type featureCollection struct {
+	Type       string         `json:"type"`
+	Features   []feature      `json:"features"`
+	Pagination *pagination    `json:"pagination,omitempty"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+type feature struct {
+	Type       string   `json:"type"`
+	Id         string   `json:"id,omitempty"`
+	Geometry   geometry `json:"geometry"`
+	Properties any      `json:"properties"`
+}
+
+type geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+func newFeatureCollection(features []feature) featureCollection {
+	return featureCollection{
+		Type:     "FeatureCollection",
+		Features: features,
+	}
+}
+
+func pointGeometry(latitude, longitude float64) geometry {
+	return geometry{
+		Type:        "Point",
+		Coordinates: [2]float64{longitude, latitude},
+	}
+}
+
+func boxGeometry(minLatitude, minLongitude, maxLatitude, maxLongitude float64) geometry {
+	return geometry{
+		Type: "Polygon",
+		Coordinates: [][][2]float64{{
+			{minLongitude, minLatitude},
+			{maxLongitude, minLatitude},
+			{maxLongitude, maxLatitude},
+			{minLongitude, maxLatitude},
+			{minLongitude, minLatitude},
+		}},
+	}
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type heatmapCellProperties struct {
+	Geohash      string          `json:"geohash,omitempty"`
+	Orders       int             `json:"orders"`
+	Subtotal     decimal.Decimal `json:"subtotal"`
+	Tax          decimal.Decimal `json:"tax"`
+	Total        decimal.Decimal `json:"total"`
+	OutsideState bool            `json:"outsideState"`
+}
+
+type HeatmapController struct {
+	uc       *usecase.HeatmapUseCase
+	location *time.Location
+}
+
+func NewHeatmapController(uc *usecase.HeatmapUseCase, location *time.Location) *HeatmapController {
+	return &HeatmapController{
+		uc:       uc,
+		location: location,
+	}
+}
+
+func (h *HeatmapController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter, err := parseListParams(q, h.location)
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+
+	params := entity.HeatmapParams{
+		Filter: filter,
+	}
+
+	if size := q.Get("cellSize"); size != "" {
+		v, err := strconv.ParseFloat(size, 64)
+		if err != nil {
+			writeError(rw, r, usecase.InvalidField("cellSize", err))
+			return
+		}
+		params.CellSize = v
+	}
+	if precision := q.Get("geohash"); precision != "" {
+		v, err := strconv.Atoi(precision)
+		if err != nil {
+			writeError(rw, r, usecase.InvalidField("geohash", err))
+			return
+		}
+		params.GeohashPrecision = v
+	}
+
+	cells, err := h.uc.Execute(r.Context(), params)
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+
+	features := make([]feature, 0, len(cells))
+	outside := 0
+	for _, cell := range cells {
+		if cell.OutsideState {
+			outside += cell.Orders
+		}
+		features = append(features, feature{
+			Type: "Feature",
+			Geometry: boxGeometry(cell.MinLatitude, cell.MinLongitude,
+				cell.MaxLatitude, cell.MaxLongitude),
+			Properties: heatmapCellProperties{
+				Geohash:      cell.Geohash,
+				Orders:       cell.Orders,
+				Subtotal:     cell.Subtotal,
+				Tax:          cell.Tax,
+				Total:        cell.Total,
+				OutsideState: cell.OutsideState,
+			},
+		})
+	}
+
+	collection := newFeatureCollection(features)
+	collection.Properties = map[string]any{
+		"cells":              len(cells),
+		"ordersOutsideState": outside,
+	}
+
+	encoded, err := json.Marshal(collection)
+	if err != nil {
+		writeError(rw, r, fmt.Errorf("encoding heatmap: %w", err))
+		return
+	}
+
+	rw.Header().Set("Content-Type", geoJSONContentType)
+	rw.WriteHeader(http.StatusOK)
+
+	_, err = rw.Write(encoded)
+	if err != nil {
+		return
+	}
+}
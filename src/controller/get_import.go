@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/usecase"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errInvalidId   = errors.New(`must be a UUID`)
+	errInvalidBool = errors.New(`must be true or false`)
+)
+
+// GetImportController reports the status and progress of an import job.
+type GetImportController struct {
+	uc *usecase.GetImportUseCase
+}
+
+func NewGetImportController(uc *usecase.GetImportUseCase) *GetImportController {
+	return &GetImportController{
+		uc: uc,
+	}
+}
+
+func (h *GetImportController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(rw, r, usecase.InvalidField("id", errInvalidId))
+		return
+	}
+
+	job, err := h.uc.Execute(r.Context(), id)
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		writeError(rw, r, fmt.Errorf("encoding import job: %w", err))
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	_, err = rw.Write(encoded)
+	if err != nil {
+		return
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"InstantWellnessKits/src/entity"
 	"InstantWellnessKits/src/usecase"
 	"encoding/json"
-	"log"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -33,42 +35,27 @@ type total struct {
 }
 
 type GetController struct {
-	uc *usecase.ListOrdersUseCase
+	uc       *usecase.ListOrdersUseCase
+	location *time.Location
 }
 
-func NewGetController(uc *usecase.ListOrdersUseCase) *GetController {
+func NewGetController(uc *usecase.ListOrdersUseCase, location *time.Location) *GetController {
 	return &GetController{
-		uc: uc,
+		uc:       uc,
+		location: location,
 	}
 }
 
 func (h *GetController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query()
-
-	params := entity.ListParams{
-		Page:   parseIntParam(q.Get("page"), 1),
-		Limit:  parseIntParam(q.Get("limit"), 20),
-		State:  q.Get("state"),
-		County: q.Get("county"),
-		City:   q.Get("city"),
-	}
-
-	if from := q.Get("from"); from != "" {
-		if t, err := time.Parse(time.DateOnly, from); err == nil {
-			params.From = &t
-		}
-	}
-	if to := q.Get("to"); to != "" {
-		if t, err := time.Parse(time.DateOnly, to); err == nil {
-			t = t.Add(24*time.Hour - time.Second)
-			params.To = &t
-		}
+	params, err := parseListParams(r.URL.Query(), h.location)
+	if err != nil {
+		writeError(rw, r, err)
+		return
 	}
 
 	result, err := h.uc.Execute(r.Context(), params)
 	if err != nil {
-		http.Error(rw, "Failed to list orders", http.StatusInternalServerError)
-		log.Println("Error executing use case:", err)
+		writeError(rw, r, err)
 		return
 	}
 
@@ -80,14 +67,21 @@ func (h *GetController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		totalPages = 1
 	}
 
+	pages := pagination{
+		Total:      result.Total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: totalPages,
+	}
+
+	if wantsGeoJSON(r) {
+		h.writeGeoJSON(rw, r, result, pages)
+		return
+	}
+
 	response := listOrdersResponse{
-		Orders: result.Orders,
-		Pagination: pagination{
-			Total:      result.Total,
-			Page:       params.Page,
-			Limit:      params.Limit,
-			TotalPages: totalPages,
-		},
+		Orders:     result.Orders,
+		Pagination: pages,
 		GlobalTotal: total{
 			Orders: result.GlobalOrders,
 			Tax:    result.GlobalTax,
@@ -102,8 +96,7 @@ func (h *GetController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 
 	encoded, err := json.Marshal(response)
 	if err != nil {
-		http.Error(rw, "Failed to encode orders", http.StatusInternalServerError)
-		log.Println("Error encoding orders:", err)
+		writeError(rw, r, fmt.Errorf("encoding orders: %w", err))
 		return
 	}
 
@@ -116,6 +109,91 @@ func (h *GetController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// orderProperties is an order without its coordinates, which GeoJSON
+// carries in the feature geometry instead.
+type orderProperties struct {
+	Subtotal         decimal.Decimal     `json:"subtotal"`
+	CompositeTaxRate decimal.Decimal     `json:"compositeTaxRate"`
+	TaxAmount        decimal.Decimal     `json:"taxAmount"`
+	TotalAmount      decimal.Decimal     `json:"totalAmount"`
+	Breakdown        entity.TaxBreakdown `json:"breakdown"`
+	Jurisdiction     entity.Jurisdiction `json:"jurisdiction"`
+	Timestamp        time.Time           `json:"timestamp"`
+}
+
+func (h *GetController) writeGeoJSON(rw http.ResponseWriter, r *http.Request, result *entity.ListResult, pages pagination) {
+	features := make([]feature, 0, len(result.Orders))
+	for _, order := range result.Orders {
+		features = append(features, feature{
+			Type:     "Feature",
+			Id:       order.Id.String(),
+			Geometry: pointGeometry(order.Latitude, order.Longitude),
+			Properties: orderProperties{
+				Subtotal:         order.Subtotal,
+				CompositeTaxRate: order.CompositeTaxRate,
+				TaxAmount:        order.TaxAmount,
+				TotalAmount:      order.TotalAmount,
+				Breakdown:        order.Breakdown,
+				Jurisdiction:     order.Jurisdiction,
+				Timestamp:        order.Timestamp,
+			},
+		})
+	}
+
+	collection := newFeatureCollection(features)
+	collection.Pagination = &pages
+
+	encoded, err := json.Marshal(collection)
+	if err != nil {
+		writeError(rw, r, fmt.Errorf("encoding orders: %w", err))
+		return
+	}
+
+	rw.Header().Set("Content-Type", geoJSONContentType)
+	rw.WriteHeader(http.StatusOK)
+
+	_, err = rw.Write(encoded)
+	if err != nil {
+		return
+	}
+}
+
+func wantsGeoJSON(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "geojson"
+	}
+	return strings.Contains(r.Header.Get("Accept"), geoJSONContentType)
+}
+
+// parseListParams reads the shared order filters. Bare from/to dates cover
+// whole days in loc, the business timezone.
+func parseListParams(q url.Values, loc *time.Location) (entity.ListParams, error) {
+	params := entity.ListParams{
+		Page:   parseIntParam(q.Get("page"), 1),
+		Limit:  parseIntParam(q.Get("limit"), 20),
+		State:  q.Get("state"),
+		County: q.Get("county"),
+		City:   q.Get("city"),
+	}
+
+	if from := q.Get("from"); from != "" {
+		t, err := entity.ParseDateBound(from, loc, false)
+		if err != nil {
+			return params, usecase.InvalidField("from", err)
+		}
+		params.From = &t
+	}
+	if to := q.Get("to"); to != "" {
+		t, err := entity.ParseDateBound(to, loc, true)
+		if err != nil {
+			return params, usecase.InvalidField("to", err)
+		}
+		params.To = &t
+	}
+
+	return params, nil
+}
+
 func parseIntParam(s string, defaultVal int) int {
 	if s == "" {
 		return defaultVal
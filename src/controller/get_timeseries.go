@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type timeseriesResponse struct {
+	Interval string            `json:"interval"`
+	Timezone string            `json:"timezone"`
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Series   []timeseriesPoint `json:"series"`
+}
+
+type timeseriesPoint struct {
+	Bucket   time.Time       `json:"bucket"`
+	County   string          `json:"county,omitempty"`
+	Orders   int             `json:"orders"`
+	Subtotal decimal.Decimal `json:"subtotal"`
+	Tax      decimal.Decimal `json:"tax"`
+	Total    decimal.Decimal `json:"total"`
+}
+
+type TimeseriesController struct {
+	uc       *usecase.TimeseriesUseCase
+	location *time.Location
+}
+
+func NewTimeseriesController(uc *usecase.TimeseriesUseCase, location *time.Location) *TimeseriesController {
+	return &TimeseriesController{
+		uc:       uc,
+		location: location,
+	}
+}
+
+func (h *TimeseriesController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	loc := h.location
+
+	params := entity.TimeseriesParams{
+		Interval:      q.Get("interval"),
+		GroupByCounty: q.Get("groupBy") == "county",
+	}
+
+	if from := q.Get("from"); from != "" {
+		t, err := entity.ParseDateBound(from, loc, false)
+		if err != nil {
+			writeError(rw, r, usecase.InvalidField("from", err))
+			return
+		}
+		params.From = t
+	}
+	if to := q.Get("to"); to != "" {
+		t, err := entity.ParseDateBound(to, loc, true)
+		if err != nil {
+			writeError(rw, r, usecase.InvalidField("to", err))
+			return
+		}
+		// Buckets use an exclusive upper bound.
+		params.To = t.Add(time.Nanosecond)
+	}
+
+	result, err := h.uc.Execute(r.Context(), params)
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+
+	response := timeseriesResponse{
+		Interval: result.Params.Interval,
+		Timezone: loc.String(),
+		From:     result.Params.From.In(loc),
+		To:       result.Params.To.In(loc),
+		Series:   make([]timeseriesPoint, 0, len(result.Points)),
+	}
+	for _, p := range result.Points {
+		response.Series = append(response.Series, timeseriesPoint{
+			Bucket:   p.Bucket,
+			County:   p.County,
+			Orders:   p.Orders,
+			Subtotal: p.Subtotal,
+			Tax:      p.Tax,
+			Total:    p.Total,
+		})
+	}
+
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		writeError(rw, r, fmt.Errorf("encoding timeseries: %w", err))
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	_, err = rw.Write(encoded)
+	if err != nil {
+		return
+	}
+}
@@ -1,6 +1,12 @@
 package controller
 
-import "net/http"
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
 
 type HealthController struct{}
 
@@ -12,3 +18,57 @@ func (h *HealthController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	rw.WriteHeader(http.StatusOK)
 	rw.Write([]byte("OK"))
 }
+
+// LivenessController reports that the process is up. It checks no
+// dependencies, so a database outage does not get the container restarted.
+type LivenessController struct{}
+
+func NewLivenessController() *LivenessController {
+	return &LivenessController{}
+}
+
+func (h *LivenessController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	writeHealthReport(rw, r, &entity.HealthReport{Status: entity.HealthOK})
+}
+
+// ReadinessController reports whether the service can take traffic. It
+// answers 503 only when a critical check fails; a degraded service is
+// still ready.
+type ReadinessController struct {
+	uc *usecase.CheckReadinessUseCase
+}
+
+func NewReadinessController(uc *usecase.CheckReadinessUseCase) *ReadinessController {
+	return &ReadinessController{
+		uc: uc,
+	}
+}
+
+func (h *ReadinessController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	report := h.uc.Execute(r.Context())
+	for _, check := range report.Checks {
+		if check.Status != entity.HealthOK {
+			slog.WarnContext(r.Context(), "Readiness check failed",
+				"check", check.Name, "critical", check.Critical, "error", check.Error)
+		}
+	}
+	writeHealthReport(rw, r, report)
+}
+
+func writeHealthReport(rw http.ResponseWriter, r *http.Request, report *entity.HealthReport) {
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if report.Status == entity.HealthUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.WriteHeader(status)
+	rw.Write(encoded)
+}
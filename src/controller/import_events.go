@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/usecase"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+
+	// eventKeepAlive keeps proxies from closing a quiet stream.
+	eventKeepAlive = 15 * time.Second
+)
+
+// ImportEventsController streams the progress of an import job as
+// Server-Sent Events: progress, row_error and a final summary. Streams end
+// when shutdown is closed; clients reconnect to another replica.
+type ImportEventsController struct {
+	uc       *usecase.WatchImportUseCase
+	shutdown <-chan struct{}
+}
+
+func NewImportEventsController(uc *usecase.WatchImportUseCase,
+	shutdown <-chan struct{}) *ImportEventsController {
+	return &ImportEventsController{
+		uc:       uc,
+		shutdown: shutdown,
+	}
+}
+
+func (h *ImportEventsController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(rw, r, usecase.InvalidField("id", errInvalidId))
+		return
+	}
+
+	rc := http.NewResponseController(rw)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	// mu serialises event writes with keep-alives; done stops keep-alives
+	// once the handler has returned.
+	var mu sync.Mutex
+	started, done := false, false
+	keepAlive := func() {
+		ticker := time.NewTicker(eventKeepAlive)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			mu.Lock()
+			if done {
+				mu.Unlock()
+				return
+			}
+			_, err := fmt.Fprint(rw, ": keep-alive\n\n")
+			if err == nil {
+				err = rc.Flush()
+			}
+			mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	// Headers are only sent with the first event, so that an unknown job
+	// is still reported as a problem response.
+	emit := func(event usecase.ImportEvent) error {
+		var data any = event.Job
+		if event.RowError != nil {
+			data = event.RowError
+		}
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !started {
+			started = true
+			rw.Header().Set("Content-Type", eventStreamContentType)
+			rw.Header().Set("Cache-Control", "no-cache")
+			rw.Header().Set("X-Accel-Buffering", "no")
+			rw.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprint(rw, "retry: 3000\n\n")
+			go keepAlive()
+		}
+
+		if _, err := fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", event.Type, encoded); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-h.shutdown:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err = h.uc.Execute(ctx, id, emit)
+
+	mu.Lock()
+	defer mu.Unlock()
+	done = true
+
+	if err != nil {
+		if !started {
+			writeError(rw, r, err)
+			return
+		}
+		slog.InfoContext(r.Context(), "Import event stream ended", "import_id", id, "error", err)
+	}
+}
@@ -2,60 +2,112 @@ package controller
 
 import (
 	"InstantWellnessKits/src/usecase"
-	"bytes"
-	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
-	"log"
+	"mime/multipart"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type ImportController struct {
-	uc *usecase.ImportOrdersUseCase
+	uc *usecase.EnqueueImportUseCase
 }
 
-func NewImportController(uc *usecase.ImportOrdersUseCase) *ImportController {
+func NewImportController(uc *usecase.EnqueueImportUseCase) *ImportController {
 	return &ImportController{
 		uc: uc,
 	}
 }
 
+// ServeHTTP streams the "file" part of a multipart upload straight into
+// import storage, so neither its size nor memory use is bounded by the
+// form parser. The file may be CSV, JSON, NDJSON or XLSX, optionally gzip or
+// zip compressed.
 func (h *ImportController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
-	err := r.ParseMultipartForm(10 << 20)
+	dryRun := false
+	if raw := r.URL.Query().Get("dryRun"); raw != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(raw)
+		if err != nil {
+			writeError(rw, r, usecase.InvalidField("dryRun", errInvalidBool))
+			return
+		}
+	}
+
+	// Large uploads take longer than the server-wide read timeout, and the
+	// write timeout runs from the same start, so the 202 would be lost too.
+	rc := http.NewResponseController(rw)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	reader, err := r.MultipartReader()
 	if err != nil {
-		http.Error(rw, "Failed to parse multipart form", http.StatusBadRequest)
+		writeProblem(rw, r, http.StatusBadRequest, codeMalformedBody, "Failed to parse multipart form.")
 		return
 	}
 
-	file, header, err := r.FormFile("file")
+	part, err := nextFilePart(reader, "file")
+	if errors.Is(err, io.EOF) {
+		writeError(rw, r, usecase.InvalidField("file", usecase.ErrMissingField))
+		return
+	}
 	if err != nil {
-		log.Println("Error retrieving file from form data:", err)
-		http.Error(rw, "Failed to retrieve file from form data", http.StatusBadRequest)
+		writeUploadError(rw, r, err)
 		return
 	}
-	defer file.Close()
+	defer part.Close()
 
-	if header.Size == 0 {
-		http.Error(rw, "Uploaded file is empty", http.StatusBadRequest)
+	job, err := h.uc.Execute(r.Context(), part.FileName(), part.Header.Get("Content-Type"), part, dryRun)
+	if err != nil {
+		writeUploadError(rw, r, err)
 		return
 	}
 
-	fileBytes, err := io.ReadAll(file)
+	encoded, err := json.Marshal(job)
 	if err != nil {
-		http.Error(rw, "Failed to read file", http.StatusInternalServerError)
+		writeError(rw, r, fmt.Errorf("encoding import job: %w", err))
 		return
 	}
 
-	go func() {
-		bytesReader := bytes.NewReader(fileBytes)
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Location", "/orders/import/"+job.Id.String())
+	rw.WriteHeader(http.StatusAccepted)
 
-		log.Println("Background import started...")
-		_, err = h.uc.Execute(context.Background(), bytesReader)
+	_, err = rw.Write(encoded)
+	if err != nil {
+		return
+	}
+}
+
+// nextFilePart skips ahead to the form part called name. It returns io.EOF
+// when the form has no such part.
+func nextFilePart(reader *multipart.Reader, name string) (*multipart.Part, error) {
+	for {
+		part, err := reader.NextPart()
 		if err != nil {
-			http.Error(rw, "Failed to import orders: "+err.Error(), http.StatusInternalServerError)
-			return
+			return nil, err
 		}
-		log.Println("Background import finished!")
-	}()
+		if part.FormName() == name {
+			return part, nil
+		}
+		part.Close()
+	}
+}
 
-	rw.WriteHeader(http.StatusAccepted)
+func writeUploadError(rw http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		writeProblem(rw, r, http.StatusRequestEntityTooLarge, codePayloadTooLarge,
+			fmt.Sprintf("Upload must not exceed %d bytes.", maxBytesErr.Limit))
+	case errors.Is(err, usecase.ErrEmptyUpload):
+		writeError(rw, r, usecase.InvalidField("file", err))
+	case errors.Is(err, multipart.ErrMessageTooLarge), errors.Is(err, io.ErrUnexpectedEOF):
+		writeProblem(rw, r, http.StatusBadRequest, codeMalformedBody, "Failed to read multipart upload.")
+	default:
+		writeError(rw, r, err)
+	}
 }
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/repository/memory"
+	"InstantWellnessKits/src/repository/storage"
+	"InstantWellnessKits/src/usecase"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestImportControllerOutlastsServerTimeouts(t *testing.T) {
+	uploads, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := usecase.NewEnqueueImportUseCase(uploads, memory.NewImportJobs(), slog.New(slog.DiscardHandler))
+
+	server := httptest.NewUnstartedServer(NewImportController(uc))
+	server.Config.ReadTimeout = 100 * time.Millisecond
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	// The upload trickles in for several times both timeouts.
+	body, pipe := io.Pipe()
+	form := multipart.NewWriter(pipe)
+	go func() {
+		part, err := form.CreateFormFile("file", "orders.csv")
+		if err != nil {
+			pipe.CloseWithError(err)
+			return
+		}
+		_, _ = io.WriteString(part, "id,longitude,latitude,timestamp,subtotal\n")
+		for range 5 {
+			time.Sleep(80 * time.Millisecond)
+			_, _ = io.WriteString(part, "1,-74.0060,40.7128,2025-01-01 10:00:00,10.00\n")
+		}
+		pipe.CloseWithError(form.Close())
+	}()
+
+	resp, err := http.Post(server.URL, form.FormDataContentType(), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/metrics"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const unmatchedRoute = "unmatched"
+
+// Instrument counts requests and observes their latency. Requests are
+// labelled with the route pattern that served them rather than their path,
+// so ids in the path do not multiply the series.
+func Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: rw}
+		next.ServeHTTP(sw, r)
+
+		status := strconv.Itoa(sw.statusCode())
+		route := routeOf(r)
+		metrics.HTTPRequests.WithLabelValues(r.Method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(r.Method, route, status).
+			Observe(time.Since(start).Seconds())
+	})
+}
+
+// routeOf returns the path of the pattern the router matched r with. The
+// router records it on r itself, so Instrument must wrap the router without
+// copying the request in between.
+func routeOf(r *http.Request) string {
+	if r.Pattern == "" {
+		return unmatchedRoute
+	}
+	_, path, found := strings.Cut(r.Pattern, " ")
+	if !found {
+		return r.Pattern
+	}
+	return path
+}
+
+// statusWriter records the status code of a response. Unwrap keeps
+// http.ResponseController working for the handlers below it.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *statusWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
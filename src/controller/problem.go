@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const problemContentType = "application/problem+json"
+
+// statusClientClosedRequest is recorded, in nginx's manner, for requests
+// the client abandoned before they were answered.
+const statusClientClosedRequest = 499
+
+// Stable, machine-readable error codes carried in every problem body.
+const (
+	codeValidationFailed     = "validation_failed"
+	codeMalformedBody        = "malformed_body"
+	codeOutOfState           = "out_of_state"
+	codeJurisdictionNotFound = "jurisdiction_not_found"
+	codeGeocodingUnavailable = "geocoding_unavailable"
+	codeGeocodingQuota       = "geocoding_quota_exceeded"
+	codeUnsupportedFormat    = "unsupported_format"
+	codeNotFound             = "not_found"
+	codeInvalidTransition    = "invalid_transition"
+	codeInternal             = "internal_error"
+)
+
+// problem is an RFC 7807 problem details body.
+type problem struct {
+	Type     string               `json:"type"`
+	Title    string               `json:"title"`
+	Status   int                  `json:"status"`
+	Detail   string               `json:"detail,omitempty"`
+	Instance string               `json:"instance,omitempty"`
+	Code     string               `json:"code"`
+	Errors   []usecase.FieldError `json:"errors,omitempty"`
+}
+
+func writeProblem(rw http.ResponseWriter, r *http.Request, status int, code, detail string,
+	fields ...usecase.FieldError) {
+	body := problem{
+		Type:     "/problems/" + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     code,
+		Errors:   fields,
+	}
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		http.Error(rw, detail, status)
+		return
+	}
+
+	rw.Header().Set("Content-Type", problemContentType)
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(encoded)
+}
+
+// writeError maps a usecase error onto its HTTP status and problem code.
+// Anything unrecognised is logged and reported as a generic 500 so internal
+// details never leak to the client.
+func writeError(rw http.ResponseWriter, r *http.Request, err error) {
+	var verr *usecase.ValidationError
+	switch {
+	case errors.Is(err, context.Canceled) && r.Context().Err() != nil:
+		// Nobody is waiting for the answer.
+		rw.WriteHeader(statusClientClosedRequest)
+	case errors.As(err, &verr):
+		writeProblem(rw, r, http.StatusBadRequest, codeValidationFailed,
+			"One or more fields are invalid.", verr.Fields...)
+	case errors.Is(err, usecase.ErrOutOfState):
+		writeProblem(rw, r, http.StatusUnprocessableEntity, codeOutOfState, err.Error())
+	case errors.Is(err, usecase.ErrJurisdictionNotFound):
+		writeProblem(rw, r, http.StatusUnprocessableEntity, codeJurisdictionNotFound,
+			usecase.ErrJurisdictionNotFound.Error())
+	case errors.Is(err, usecase.ErrGeocodingQuotaExceeded):
+		var quotaErr *usecase.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			seconds := max(int(math.Ceil(time.Until(quotaErr.ResetAt).Seconds())), 1)
+			rw.Header().Set("Retry-After", strconv.Itoa(seconds))
+		}
+		writeProblem(rw, r, http.StatusServiceUnavailable, codeGeocodingQuota,
+			usecase.ErrGeocodingQuotaExceeded.Error())
+	case errors.Is(err, usecase.ErrGeocodingUnavailable):
+		slog.WarnContext(r.Context(), "Geocoding unavailable", "error", err)
+		writeProblem(rw, r, http.StatusServiceUnavailable, codeGeocodingUnavailable,
+			usecase.ErrGeocodingUnavailable.Error())
+	case errors.Is(err, usecase.ErrInvalidTransition):
+		writeProblem(rw, r, http.StatusConflict, codeInvalidTransition, usecase.ErrInvalidTransition.Error())
+	case errors.Is(err, entity.ErrNotFound):
+		writeProblem(rw, r, http.StatusNotFound, codeNotFound, "The requested resource does not exist.")
+	default:
+		slog.ErrorContext(r.Context(), "Error handling request",
+			"method", r.Method, "path", r.URL.Path, "error", err)
+		writeProblem(rw, r, http.StatusInternalServerError, codeInternal,
+			"An unexpected error occurred.")
+	}
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"validation", usecase.InvalidField("latitude", usecase.ErrInvalidLatitude),
+			http.StatusBadRequest, codeValidationFailed},
+		{"out of state", fmt.Errorf("%w (got: Ohio)", usecase.ErrOutOfState),
+			http.StatusUnprocessableEntity, codeOutOfState},
+		{"jurisdiction", fmt.Errorf("%w: %w", usecase.ErrJurisdictionNotFound, entity.ErrNotFound),
+			http.StatusUnprocessableEntity, codeJurisdictionNotFound},
+		{"geocoding", fmt.Errorf("%w: %w", usecase.ErrGeocodingUnavailable, errors.New("timeout")),
+			http.StatusServiceUnavailable, codeGeocodingUnavailable},
+		{"quota", fmt.Errorf("%w: %w", usecase.ErrGeocodingUnavailable, &usecase.QuotaExceededError{}),
+			http.StatusServiceUnavailable, codeGeocodingQuota},
+		{"transition", usecase.ErrInvalidTransition, http.StatusConflict, codeInvalidTransition},
+		{"not found", entity.ErrNotFound, http.StatusNotFound, codeNotFound},
+		{"internal", errors.New("disk full"), http.StatusInternalServerError, codeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeError(rec, httptest.NewRequest(http.MethodPost, "/orders", nil), tt.err)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != problemContentType {
+				t.Errorf("Content-Type = %q, want %q", ct, problemContentType)
+			}
+			var body problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Code != tt.code || body.Status != tt.status || body.Instance != "/orders" {
+				t.Errorf("body = %+v, want code %q, status %d, instance /orders", body, tt.code, tt.status)
+			}
+		})
+	}
+}
+
+func TestWriteErrorQuotaRetryAfter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := &usecase.QuotaExceededError{ResetAt: time.Now().Add(90 * time.Minute)}
+	writeError(rec, httptest.NewRequest(http.MethodPost, "/orders", nil), err)
+
+	if got := rec.Header().Get("Retry-After"); got != "5400" && got != "5399" {
+		t.Errorf("Retry-After = %q, want about 5400", got)
+	}
+}
+
+func TestWriteErrorClientGone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequestWithContext(ctx, http.MethodPost, "/orders", nil)
+	writeError(rec, r, fmt.Errorf("geocoding: %w", context.Canceled))
+
+	if rec.Code != statusClientClosedRequest || rec.Body.Len() > 0 {
+		t.Errorf("status = %d, body %q; want %d and no body", rec.Code, rec.Body, statusClientClosedRequest)
+	}
+}
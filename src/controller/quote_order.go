@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/usecase"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+type quoteOrderRequest struct {
+	Latitude  *float64         `json:"latitude"`
+	Longitude *float64         `json:"longitude"`
+	Subtotal  *decimal.Decimal `json:"subtotal"`
+}
+
+type QuoteController struct {
+	uc *usecase.QuoteOrderUseCase
+}
+
+func NewQuoteController(uc *usecase.QuoteOrderUseCase) *QuoteController {
+	return &QuoteController{
+		uc: uc,
+	}
+}
+
+func (h *QuoteController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	var body quoteOrderRequest
+	if !decodeJSON(rw, r, &body) {
+		return
+	}
+
+	verr := &usecase.ValidationError{}
+	if body.Latitude == nil {
+		verr.Add("latitude", usecase.ErrMissingField)
+	}
+	if body.Longitude == nil {
+		verr.Add("longitude", usecase.ErrMissingField)
+	}
+	if body.Subtotal == nil {
+		verr.Add("subtotal", usecase.ErrMissingField)
+	}
+	if err := verr.OrNil(); err != nil {
+		writeError(rw, r, err)
+		return
+	}
+
+	quote, err := h.uc.Execute(r.Context(), *body.Latitude, *body.Longitude, *body.Subtotal)
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+
+	encoded, err := json.Marshal(quote)
+	if err != nil {
+		writeError(rw, r, fmt.Errorf("encoding quote: %w", err))
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	_, err = rw.Write(encoded)
+	if err != nil {
+		return
+	}
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/entity"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	codeRateLimited = "rate_limited"
+
+	bucketIdleTTL = 10 * time.Minute
+)
+
+// RateLimiter is a per-client token bucket. Clients are identified by
+// their authenticated principal, falling back to the client IP.
+type RateLimiter struct {
+	name       string
+	rate       float64 // tokens per second
+	burst      float64
+	trustProxy bool
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter allows perMinute requests per client per minute with
+// bursts of up to burst requests. name identifies the budget in the
+// RateLimit-Policy header.
+func NewRateLimiter(name string, perMinute float64, burst int, trustProxy bool) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		name:       name,
+		rate:       perMinute / 60,
+		burst:      float64(burst),
+		trustProxy: trustProxy,
+		buckets:    make(map[string]*bucket),
+		now:        time.Now,
+	}
+}
+
+func (l *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		allowed, remaining, wait := l.take(l.clientKey(r))
+
+		h := rw.Header()
+		h.Set("RateLimit-Policy", fmt.Sprintf(`%d;w=60;name="%s"`, int(l.rate*60), l.name))
+		h.Set("RateLimit-Limit", strconv.Itoa(int(l.burst)))
+		h.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+
+		if !allowed {
+			seconds := strconv.Itoa(int(math.Ceil(wait.Seconds())))
+			h.Set("RateLimit-Reset", seconds)
+			h.Set("Retry-After", seconds)
+			writeProblem(rw, r, http.StatusTooManyRequests, codeRateLimited,
+				"Too many requests, retry after "+seconds+" seconds.")
+			return
+		}
+
+		h.Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil(l.untilFull(remaining).Seconds()))))
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// take consumes one token for key. It returns whether the request is
+// allowed, the whole tokens left and, when refused, how long until a token
+// becomes available.
+func (l *RateLimiter) take(key string) (bool, int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, 0, wait
+	}
+
+	b.tokens--
+	return true, int(b.tokens), 0
+}
+
+func (l *RateLimiter) untilFull(remaining int) time.Duration {
+	missing := l.burst - float64(remaining)
+	return time.Duration(missing / l.rate * float64(time.Second))
+}
+
+// sweep drops buckets that have been idle long enough to be full again.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketIdleTTL {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > bucketIdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	if principal, ok := entity.PrincipalFromContext(r.Context()); ok {
+		return principal.Subject
+	}
+	return "ip:" + clientIP(r, l.trustProxy)
+}
+
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/entity"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLimiter(perMinute float64, burst int, trustProxy bool) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	l := NewRateLimiter("test", perMinute, burst, trustProxy)
+	l.now = clock.now
+	return l, clock
+}
+
+func limitedRequest(l *RateLimiter, configure func(*http.Request)) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	if configure != nil {
+		configure(r)
+	}
+	rec := httptest.NewRecorder()
+	l.Limit(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})).ServeHTTP(rec, r)
+	return rec
+}
+
+func TestRateLimiterBurstAndRefill(t *testing.T) {
+	l, clock := newTestLimiter(30, 3, false)
+
+	for _, want := range []struct{ remaining, reset string }{{"2", "2"}, {"1", "4"}, {"0", "6"}} {
+		rec := limitedRequest(l, nil)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+		}
+		h := rec.Header()
+		if h.Get("RateLimit-Remaining") != want.remaining || h.Get("RateLimit-Reset") != want.reset {
+			t.Errorf("remaining, reset = %s, %s, want %s, %s",
+				h.Get("RateLimit-Remaining"), h.Get("RateLimit-Reset"), want.remaining, want.reset)
+		}
+		if h.Get("RateLimit-Limit") != "3" || h.Get("RateLimit-Policy") != `30;w=60;name="test"` {
+			t.Errorf("limit, policy = %s, %s", h.Get("RateLimit-Limit"), h.Get("RateLimit-Policy"))
+		}
+	}
+
+	// Half a token has come back after a second; the next one is a
+	// second away.
+	clock.advance(time.Second)
+	rec := limitedRequest(l, nil)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != problemContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, problemContentType)
+	}
+
+	clock.advance(time.Second)
+	if rec := limitedRequest(l, nil); rec.Code != http.StatusNoContent {
+		t.Errorf("status after refill = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	// Idle time refills the bucket only up to the burst.
+	clock.advance(time.Hour)
+	for i := range 3 {
+		if rec := limitedRequest(l, nil); rec.Code != http.StatusNoContent {
+			t.Fatalf("request %d after an hour: status = %d", i, rec.Code)
+		}
+	}
+	if rec := limitedRequest(l, nil); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("request beyond the burst: status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestRateLimiterClientKey(t *testing.T) {
+	fromIP := func(ip string) func(*http.Request) {
+		return func(r *http.Request) { r.RemoteAddr = ip + ":1234" }
+	}
+	forwardedFor := func(ip string) func(*http.Request) {
+		return func(r *http.Request) { r.Header.Set("X-Forwarded-For", ip+", 10.0.0.1") }
+	}
+	as := func(subject string) func(*http.Request) {
+		return func(r *http.Request) {
+			*r = *r.WithContext(entity.ContextWithPrincipal(r.Context(), &entity.Principal{Subject: subject}))
+		}
+	}
+
+	tests := []struct {
+		name        string
+		trustProxy  bool
+		first, then func(*http.Request)
+		shared      bool
+	}{
+		{"same ip", false, fromIP("192.0.2.7"), fromIP("192.0.2.7"), true},
+		{"other ip", false, fromIP("192.0.2.7"), fromIP("192.0.2.8"), false},
+		{"forwarded ignored", false, forwardedFor("198.51.100.1"), forwardedFor("198.51.100.2"), true},
+		{"forwarded trusted", true, forwardedFor("198.51.100.1"), forwardedFor("198.51.100.2"), false},
+		{"same forwarded client", true, forwardedFor("198.51.100.1"), forwardedFor("198.51.100.1"), true},
+		{"principals behind one ip", false, as("key:a"), as("key:b"), false},
+		{"same principal", false, as("key:a"), as("key:a"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestLimiter(1, 1, tt.trustProxy)
+			if rec := limitedRequest(l, tt.first); rec.Code != http.StatusNoContent {
+				t.Fatalf("first status = %d", rec.Code)
+			}
+			rec := limitedRequest(l, tt.then)
+			if shared := rec.Code == http.StatusTooManyRequests; shared != tt.shared {
+				t.Errorf("second status = %d, want the bucket shared: %v", rec.Code, tt.shared)
+			}
+		})
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	l, clock := newTestLimiter(60, 5, false)
+	for _, ip := range []string{"192.0.2.1", "192.0.2.2"} {
+		limitedRequest(l, func(r *http.Request) { r.RemoteAddr = ip + ":1" })
+	}
+
+	clock.advance(bucketIdleTTL / 2)
+	limitedRequest(l, nil)
+	if len(l.buckets) != 2 {
+		t.Fatalf("%d buckets before the TTL, want 2", len(l.buckets))
+	}
+
+	// Only the bucket used since stays.
+	clock.advance(bucketIdleTTL/2 + time.Second)
+	limitedRequest(l, func(r *http.Request) { r.RemoteAddr = "192.0.2.3:1" })
+	if len(l.buckets) != 2 {
+		t.Errorf("%d buckets after the sweep, want 2", len(l.buckets))
+	}
+	if _, ok := l.buckets["ip:192.0.2.2"]; ok {
+		t.Error("idle bucket was kept")
+	}
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/entity"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const (
+	requestIdHeader = "X-Request-Id"
+
+	maxRequestIdLength = 128
+)
+
+// RequestId tags every request with an id, taken from the X-Request-Id
+// header set by a proxy or client when present and generated otherwise.
+// The id is echoed in the response and attached to the request's logs.
+func RequestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(requestIdHeader)
+		if !validRequestId(requestId) {
+			requestId = uuid.NewString()
+		}
+
+		rw.Header().Set(requestIdHeader, requestId)
+		next.ServeHTTP(rw, r.WithContext(entity.ContextWithRequestId(r.Context(), requestId)))
+	})
+}
+
+// validRequestId accepts short printable ASCII ids, so a client cannot
+// inject line breaks or arbitrary amounts of text into the logs.
+func validRequestId(id string) bool {
+	if id == "" || len(id) > maxRequestIdLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// TaxSettingsController reads (GET) and replaces (PUT) the calling tenant's
+// tax exemption and rate overrides.
+type TaxSettingsController struct {
+	uc *usecase.ManageTaxSettingsUseCase
+}
+
+func NewTaxSettingsController(uc *usecase.ManageTaxSettingsUseCase) *TaxSettingsController {
+	return &TaxSettingsController{
+		uc: uc,
+	}
+}
+
+func (h *TaxSettingsController) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPut {
+		var body entity.TaxSettings
+		if !decodeJSON(rw, r, &body) {
+			return
+		}
+		if err := h.uc.Save(r.Context(), &body); err != nil {
+			writeError(rw, r, err)
+			return
+		}
+	}
+
+	settings, err := h.uc.Get(r.Context())
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		writeError(rw, r, fmt.Errorf("encoding tax settings: %w", err))
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	_, err = rw.Write(encoded)
+	if err != nil {
+		return
+	}
+}
@@ -0,0 +1,12 @@
+package controller
+
+import "net/http"
+
+// SpanName names the span of a request after the route that served it,
+// such as "GET /orders/import/{id}".
+func SpanName(_ string, r *http.Request) string {
+	if r.Pattern == "" {
+		return r.Method + " " + unmatchedRoute
+	}
+	return r.Pattern
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Role string
+
+const (
+	RoleAnalyst     Role = "analyst"
+	RoleOrderWriter Role = "order_writer"
+	RoleImporter    Role = "importer"
+	RoleRateAdmin   Role = "rate_admin"
+)
+
+var Roles = []Role{RoleAnalyst, RoleOrderWriter, RoleImporter, RoleRateAdmin}
+
+func ParseRole(s string) (Role, bool) {
+	role := Role(s)
+	return role, slices.Contains(Roles, role)
+}
+
+type APIKey struct {
+	Id        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Roles     []Role     `json:"roles"`
+	TenantId  string     `json:"tenantId"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+func NewAPIKey(name, prefix, tenantId string, roles []Role) *APIKey {
+	return &APIKey{
+		Id:        uuid.New(),
+		Name:      name,
+		Prefix:    prefix,
+		Roles:     roles,
+		TenantId:  tenantId,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject  string
+	TenantId string
+	Roles    []Role
+}
+
+func (p *Principal) HasAnyRole(roles ...Role) bool {
+	for _, role := range roles {
+		if slices.Contains(p.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "errors"
+
+// ErrNotFound is wrapped by repositories and services when the requested
+// record or lookup result does not exist.
+var ErrNotFound = errors.New(`not found`)
+
+// ErrLeaseLost is returned when a worker updates a job it no longer owns,
+// typically because its lease expired and another replica reclaimed it.
+var ErrLeaseLost = errors.New(`lease lost`)
+
+// ErrRejected is wrapped by repositories when the database refuses a write
+// because of the data itself, such as a constraint violation or a numeric
+// overflow, rather than because it is unavailable.
+var ErrRejected = errors.New(`rejected by the database`)
+
+// ErrMixedTenants is returned by repositories when a batch write holds
+// orders of more than one tenant, since a batch is written under one tenant.
+var ErrMixedTenants = errors.New(`batch contains orders of more than one tenant`)
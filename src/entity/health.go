@@ -0,0 +1,25 @@
+package entity
+
+type HealthStatus string
+
+const (
+	HealthOK          HealthStatus = "ok"
+	HealthDegraded    HealthStatus = "degraded"
+	HealthUnavailable HealthStatus = "unavailable"
+)
+
+// HealthCheckResult is the outcome of probing one dependency. A failed
+// critical check makes the service unavailable; any other failed check
+// only degrades it.
+type HealthCheckResult struct {
+	Name      string       `json:"name"`
+	Status    HealthStatus `json:"status"`
+	Critical  bool         `json:"critical"`
+	LatencyMs float64      `json:"latencyMs"`
+	Error     string       `json:"error,omitempty"`
+}
+
+type HealthReport struct {
+	Status HealthStatus        `json:"status"`
+	Checks []HealthCheckResult `json:"checks,omitempty"`
+}
@@ -0,0 +1,134 @@
+package entity
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ImportStatus string
+
+const (
+	ImportQueued    ImportStatus = "queued"
+	ImportRunning   ImportStatus = "running"
+	ImportPaused    ImportStatus = "paused"
+	ImportCompleted ImportStatus = "completed"
+	ImportFailed    ImportStatus = "failed"
+	ImportCancelled ImportStatus = "cancelled"
+)
+
+// Finished reports whether a job in this status will never run again.
+func (s ImportStatus) Finished() bool {
+	return s == ImportCompleted || s == ImportFailed || s == ImportCancelled
+}
+
+// MaxImportErrorSamples bounds the row errors kept per job.
+const MaxImportErrorSamples = 20
+
+// ImportRowError is a sample of a row that could not be imported.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ImportJob is a queued or running import of an uploaded file. Every row
+// up to and including CheckpointRow has been durably processed, so a job
+// resumes right after it. A new job starts at 0: JSON and NDJSON rows are
+// numbered from 1, and CSV and XLSX readers skip their own header rows.
+// A queued job with a ResumeAt is waiting for the geocoding quota to reset
+// and is not claimed before then; its Error says why.
+type ImportJob struct {
+	Id             uuid.UUID        `json:"id"`
+	TenantId       string           `json:"-"`
+	FileKey        string           `json:"-"`
+	FileName       string           `json:"fileName"`
+	Format         string           `json:"format,omitempty"`
+	Status         ImportStatus     `json:"status"`
+	DryRun         bool             `json:"dryRun"`
+	RowsRead       int              `json:"rowsRead"`
+	RowsGeocoded   int              `json:"rowsGeocoded"`
+	RowsImported   int              `json:"rowsImported"`
+	RowsFailed     int              `json:"rowsFailed"`
+	BatchesFlushed int              `json:"batchesFlushed"`
+	CheckpointRow  int              `json:"checkpointRow"`
+	ErrorSamples   []ImportRowError `json:"errorSamples"`
+	Report         *ImportReport    `json:"report"`
+	Error          string           `json:"error,omitempty"`
+	RolledBack     bool             `json:"rolledBack,omitempty"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	UpdatedAt      time.Time        `json:"updatedAt"`
+	StartedAt      *time.Time       `json:"startedAt,omitempty"`
+	FinishedAt     *time.Time       `json:"finishedAt,omitempty"`
+	ResumeAt       *time.Time       `json:"resumeAt,omitempty"`
+}
+
+// NewImportJob queues fileName for import. An empty format is detected
+// from the file. A dry run goes through every step except inserting the
+// orders.
+func NewImportJob(tenantId, fileName, format string, dryRun bool) *ImportJob {
+	id := uuid.New()
+	now := time.Now()
+	return &ImportJob{
+		Id:           id,
+		TenantId:     tenantId,
+		FileKey:      id.String(),
+		FileName:     fileName,
+		Format:       format,
+		Status:       ImportQueued,
+		DryRun:       dryRun,
+		ErrorSamples: make([]ImportRowError, 0),
+		Report:       NewImportReport(),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// ImportProgress is the state of a running job. It is published live as
+// rows are processed and persisted at every checkpoint.
+type ImportProgress struct {
+	CheckpointRow  int
+	RowsRead       int
+	RowsGeocoded   int
+	RowsImported   int
+	RowsFailed     int
+	BatchesFlushed int
+	ErrorSamples   []ImportRowError
+	Report         *ImportReport
+}
+
+// Progress returns the job's last checkpointed progress.
+func (j *ImportJob) Progress() ImportProgress {
+	return ImportProgress{
+		CheckpointRow:  j.CheckpointRow,
+		RowsRead:       j.RowsRead,
+		RowsGeocoded:   j.RowsGeocoded,
+		RowsImported:   j.RowsImported,
+		RowsFailed:     j.RowsFailed,
+		BatchesFlushed: j.BatchesFlushed,
+		ErrorSamples:   slices.Clone(j.ErrorSamples),
+		Report:         j.Report.Clone(),
+	}
+}
+
+// WithProgress returns a copy of the job with progress applied.
+func (j *ImportJob) WithProgress(p ImportProgress) *ImportJob {
+	c := *j
+	c.CheckpointRow = p.CheckpointRow
+	c.RowsRead = p.RowsRead
+	c.RowsGeocoded = p.RowsGeocoded
+	c.RowsImported = p.RowsImported
+	c.RowsFailed = p.RowsFailed
+	c.BatchesFlushed = p.BatchesFlushed
+	c.ErrorSamples = slices.Clone(p.ErrorSamples)
+	c.Report = p.Report.Clone()
+	return &c
+}
+
+// AddError records a failed row, keeping the first MaxImportErrorSamples.
+func (p *ImportProgress) AddError(row int, err error) {
+	p.RowsFailed++
+	if len(p.ErrorSamples) < MaxImportErrorSamples {
+		p.ErrorSamples = append(p.ErrorSamples, ImportRowError{Row: row, Message: err.Error()})
+	}
+}
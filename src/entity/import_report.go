@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"maps"
+
+	"github.com/shopspring/decimal"
+)
+
+// ImportReport summarises what an import did, or in a dry run would do:
+// rows by outcome and the subtotal and tax of the valid rows per county.
+type ImportReport struct {
+	Outcomes map[string]int          `json:"outcomes"`
+	Subtotal decimal.Decimal         `json:"subtotal"`
+	Tax      decimal.Decimal         `json:"tax"`
+	Counties map[string]CountyTotals `json:"counties"`
+}
+
+type CountyTotals struct {
+	Orders   int             `json:"orders"`
+	Subtotal decimal.Decimal `json:"subtotal"`
+	Tax      decimal.Decimal `json:"tax"`
+}
+
+func NewImportReport() *ImportReport {
+	return &ImportReport{
+		Outcomes: make(map[string]int),
+		Subtotal: decimal.Zero,
+		Tax:      decimal.Zero,
+		Counties: make(map[string]CountyTotals),
+	}
+}
+
+func (r *ImportReport) AddOutcome(outcome string) {
+	r.Outcomes[outcome]++
+}
+
+func (r *ImportReport) AddOrder(order *Order) {
+	r.Subtotal = r.Subtotal.Add(order.Subtotal)
+	r.Tax = r.Tax.Add(order.TaxAmount)
+
+	totals := r.Counties[order.Jurisdiction.County]
+	totals.Orders++
+	totals.Subtotal = totals.Subtotal.Add(order.Subtotal)
+	totals.Tax = totals.Tax.Add(order.TaxAmount)
+	r.Counties[order.Jurisdiction.County] = totals
+}
+
+func (r *ImportReport) Clone() *ImportReport {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	c.Outcomes = maps.Clone(r.Outcomes)
+	c.Counties = maps.Clone(r.Counties)
+	return &c
+}
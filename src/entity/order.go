@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +19,9 @@ type Order struct {
 	Breakdown        TaxBreakdown    `json:"breakdown"`
 	Jurisdiction     Jurisdiction    `json:"jurisdiction"`
 	Timestamp        time.Time       `json:"timestamp"`
+	TenantId         string          `json:"-"`
+	ImportJobId      *uuid.UUID      `json:"-"`
+	ImportRow        int             `json:"-"`
 }
 
 func NewOrder(latitude, longitude float64, subtotal, compositeTaxRate,
@@ -70,13 +74,16 @@ func NewJurisdiction(state, county, city, special string) *Jurisdiction {
 }
 
 type ListParams struct {
-	Page   int
-	Limit  int
-	State  string
-	City   string
-	County string
-	From   *time.Time
-	To     *time.Time
+	TenantId string
+	Page     int
+	Limit    int
+	State    string
+	City     string
+	County   string
+	From     *time.Time
+	To       *time.Time
+	// Last24hFrom is the start of the rolling "last 24 hours" window.
+	Last24hFrom time.Time
 }
 
 type ListResult struct {
@@ -89,3 +96,102 @@ type ListResult struct {
 	Last24hTax    decimal.Decimal
 	Last24hGrand  decimal.Decimal
 }
+
+const (
+	IntervalHour  = "hour"
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// TruncateToInterval returns the start of the hour, day, week (from
+// Monday) or month containing t, taken as wall-clock time in loc. Hours
+// keep the offset of t, so the repeated hour when clocks go back is a
+// bucket of its own.
+func TruncateToInterval(t time.Time, interval string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	year, month, day := t.Date()
+	switch interval {
+	case IntervalHour:
+		return t.Round(0).Add(-time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second -
+			time.Duration(t.Nanosecond()))
+	case IntervalWeek:
+		sinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-sinceMonday, 0, 0, 0, 0, loc)
+	case IntervalMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, loc)
+	}
+}
+
+type TimeseriesParams struct {
+	TenantId      string
+	Interval      string
+	From          time.Time
+	To            time.Time
+	GroupByCounty bool
+	Location      *time.Location
+}
+
+type TimeseriesPoint struct {
+	Bucket   time.Time
+	County   string
+	Orders   int
+	Subtotal decimal.Decimal
+	Tax      decimal.Decimal
+	Total    decimal.Decimal
+}
+
+type TimeseriesResult struct {
+	Params TimeseriesParams
+	Points []*TimeseriesPoint
+}
+
+// Grid describes a regular latitude/longitude lattice anchored at
+// OriginLatitude/OriginLongitude.
+type Grid struct {
+	OriginLatitude  float64
+	OriginLongitude float64
+	LatitudeStep    float64
+	LongitudeStep   float64
+}
+
+// Cell returns the row and column of the cell containing a point.
+func (g Grid) Cell(latitude, longitude float64) (int64, int64) {
+	return int64(math.Floor((latitude - g.OriginLatitude) / g.LatitudeStep)),
+		int64(math.Floor((longitude - g.OriginLongitude) / g.LongitudeStep))
+}
+
+type HeatmapParams struct {
+	Filter           ListParams
+	CellSize         float64
+	GeohashPrecision int
+}
+
+type HeatmapCell struct {
+	Row          int64
+	Column       int64
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+	Geohash      string
+	Orders       int
+	Subtotal     decimal.Decimal
+	Tax          decimal.Decimal
+	Total        decimal.Decimal
+	OutsideState bool
+}
+
+// Quote is the tax calculation for a delivery without a persisted order.
+type Quote struct {
+	Latitude         float64         `json:"latitude"`
+	Longitude        float64         `json:"longitude"`
+	Subtotal         decimal.Decimal `json:"subtotal"`
+	CompositeTaxRate decimal.Decimal `json:"compositeTaxRate"`
+	TaxAmount        decimal.Decimal `json:"taxAmount"`
+	TotalAmount      decimal.Decimal `json:"totalAmount"`
+	Breakdown        TaxBreakdown    `json:"breakdown"`
+	Jurisdiction     Jurisdiction    `json:"jurisdiction"`
+}
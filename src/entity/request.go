@@ -0,0 +1,14 @@
+package entity
+
+import "context"
+
+type requestIdKey struct{}
+
+func ContextWithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+func RequestIdFromContext(ctx context.Context) (string, bool) {
+	requestId, ok := ctx.Value(requestIdKey{}).(string)
+	return requestId, ok
+}
@@ -0,0 +1,27 @@
+package entity
+
+import "github.com/shopspring/decimal"
+
+// DefaultTenant owns all data created before multi-tenancy and every
+// request made while authentication is disabled.
+const DefaultTenant = "default"
+
+// TaxSettings are a tenant's own adjustments on top of the published
+// New York rates.
+type TaxSettings struct {
+	Exempt    bool           `json:"exempt"`
+	Overrides []RateOverride `json:"overrides"`
+}
+
+// RateOverride replaces the published rate of one jurisdiction for a tenant.
+type RateOverride struct {
+	JurisdictionName string          `json:"jurisdiction"`
+	StateRate        decimal.Decimal `json:"stateRate"`
+	CountyRate       decimal.Decimal `json:"countyRate"`
+	CityRate         decimal.Decimal `json:"cityRate"`
+	SpecialRate      decimal.Decimal `json:"specialRate"`
+}
+
+func (o RateOverride) CompositeRate() decimal.Decimal {
+	return o.StateRate.Add(o.CountyRate).Add(o.CityRate).Add(o.SpecialRate)
+}
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// Layouts for timestamps that carry no zone of their own. They are read as
+// wall-clock time in the business timezone. Fractional seconds are optional.
+const (
+	naiveDateTime  = "2006-01-02 15:04:05.999999999"
+	naiveTDateTime = "2006-01-02T15:04:05.999999999"
+)
+
+var ErrInvalidTimestamp = errors.New(`invalid timestamp`)
+
+// ParseTimestamp accepts RFC 3339 timestamps, which keep their own offset,
+// and naive "YYYY-MM-DD HH:MM:SS[.fffffffff]" timestamps (space or "T"
+// separated), which are interpreted in loc.
+func ParseTimestamp(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{naiveDateTime, naiveTDateTime} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrInvalidTimestamp
+}
+
+// ParseDateBound parses a date filter bound. A bare date resolves to the
+// start of that day in loc, or, when end is set, to the last instant of it.
+// Any value accepted by ParseTimestamp is used as-is.
+func ParseDateBound(value string, loc *time.Location, end bool) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, value, loc); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return t, nil
+	}
+	return ParseTimestamp(value, loc)
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDateBound(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		end   bool
+		want  string
+	}{
+		{"offset is kept", "2025-03-10T12:00:00+02:00", false, "2025-03-10T10:00:00Z"},
+		{"UTC", "2025-03-10T12:00:00Z", true, "2025-03-10T12:00:00Z"},
+		{"fractional seconds", "2025-03-10T12:00:00.123456789-04:00", false, "2025-03-10T16:00:00.123456789Z"},
+		{"missing zone is local time", "2025-03-10 12:00:00", false, "2025-03-10T16:00:00Z"},
+		{"missing zone with T", "2025-01-10T12:00:00.5", false, "2025-01-10T17:00:00.5Z"},
+		{"date is the start of the local day", "2025-03-10", false, "2025-03-10T04:00:00Z"},
+		{"date as to is the last nanosecond of the local day", "2025-03-10", true, "2025-03-11T03:59:59.999999999Z"},
+		// Clocks go forward on 9 March, so that day ends an hour earlier.
+		{"to of a 23 hour day", "2025-03-09", true, "2025-03-10T03:59:59.999999999Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDateBound(tt.value, loc, tt.end)
+			if err != nil {
+				t.Fatalf("ParseDateBound(%q): %v", tt.value, err)
+			}
+			if want, _ := time.Parse(time.RFC3339Nano, tt.want); !got.Equal(want) {
+				t.Errorf("ParseDateBound(%q) = %v, want %s", tt.value, got.UTC(), tt.want)
+			}
+		})
+	}
+
+	for _, value := range []string{"", "yesterday", "2025-13-01", "2025-03-10T25:00:00Z", "10/03/2025",
+		"2025-03-10 12:00", "1741608000"} {
+		if _, err := ParseDateBound(value, loc, false); !errors.Is(err, ErrInvalidTimestamp) {
+			t.Errorf("ParseDateBound(%q) = %v, want %v", value, err, ErrInvalidTimestamp)
+		}
+	}
+}
+
+func TestParseTimestampRejectsDates(t *testing.T) {
+	// Only filter bounds may be bare dates; an order needs a time of day.
+	if _, err := ParseTimestamp("2025-03-10", time.UTC); !errors.Is(err, ErrInvalidTimestamp) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidTimestamp)
+	}
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "iwk"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time to serve HTTP requests, by method, route pattern and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	GeocodingRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "geocoding_requests_total",
+		Help:      "Outbound geocoding calls by provider and result (ok, not_found or error).",
+	}, []string{"provider", "result"})
+
+	GeocodingDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "geocoding_request_duration_seconds",
+		Help:      "Latency of outbound geocoding calls by provider.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider"})
+
+	TaxRateResolutions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tax_rate_resolutions_total",
+		Help: "Tax rates resolved by the jurisdiction level that matched: city, or the county " +
+			"and state fallbacks; exempt for tax-exempt tenants.",
+	}, []string{"level"})
+
+	ImportRows = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "import_rows_total",
+		Help:      "Import rows processed by outcome; every outcome but valid is a failed row.",
+	}, []string{"outcome"})
+
+	BatchInsertDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "order_batch_insert_duration_seconds",
+		Help:      "Time to insert a batch of orders, by method (copy or insert).",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"method"})
+)
+
+// Handler serves every registered metric in the Prometheus format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterDB exports the connection pool statistics of db, labelled with
+// name.
+func RegisterDB(db *sql.DB, name string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, name))
+}
@@ -0,0 +1,16 @@
+DROP POLICY IF EXISTS tenant_isolation ON tenants;
+DROP POLICY IF EXISTS tenant_isolation ON tenant_rate_overrides;
+DROP POLICY IF EXISTS tenant_isolation ON orders;
+
+ALTER TABLE orders DISABLE ROW LEVEL SECURITY;
+
+DROP TABLE IF EXISTS tenant_rate_overrides;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+DROP INDEX IF EXISTS idx_orders_tenant_timestamp;
+ALTER TABLE orders DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
+
+REVOKE ALL ON tax_rates FROM iwk_tenant;
+DROP ROLE IF EXISTS iwk_tenant;
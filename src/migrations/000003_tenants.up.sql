@@ -0,0 +1,57 @@
+CREATE TABLE tenants (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    tax_exempt BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO tenants (id, name) VALUES ('default', 'Default');
+
+ALTER TABLE orders ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE orders ALTER COLUMN tenant_id DROP DEFAULT;
+CREATE INDEX idx_orders_tenant_timestamp ON orders(tenant_id, timestamp DESC);
+
+ALTER TABLE api_keys ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE api_keys ALTER COLUMN tenant_id DROP DEFAULT;
+
+CREATE TABLE tenant_rate_overrides (
+    tenant_id VARCHAR(64) NOT NULL REFERENCES tenants(id),
+    jurisdiction_name VARCHAR(100) NOT NULL,
+    composite_rate DECIMAL(7, 5) NOT NULL,
+    state_rate DECIMAL(7, 5) NOT NULL,
+    county_rate DECIMAL(7, 5) NOT NULL,
+    city_rate DECIMAL(7, 5) NOT NULL,
+    special_rate DECIMAL(7, 5) NOT NULL,
+    PRIMARY KEY (tenant_id, jurisdiction_name)
+);
+
+-- Tenant-scoped queries switch to this role with SET LOCAL ROLE, so the
+-- policies below apply even when the service logs in as the table owner.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = 'iwk_tenant') THEN
+        CREATE ROLE iwk_tenant NOLOGIN;
+    END IF;
+END
+$$;
+
+GRANT iwk_tenant TO CURRENT_USER;
+GRANT SELECT, INSERT, UPDATE, DELETE ON orders, tenant_rate_overrides TO iwk_tenant;
+GRANT SELECT, UPDATE ON tenants TO iwk_tenant;
+GRANT SELECT ON tax_rates TO iwk_tenant;
+
+ALTER TABLE orders ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_rate_overrides ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenants ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation ON orders
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+CREATE POLICY tenant_isolation ON tenant_rate_overrides
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+CREATE POLICY tenant_isolation ON tenants
+    USING (id = current_setting('app.tenant_id', true))
+    WITH CHECK (id = current_setting('app.tenant_id', true));
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_orders_import_row;
+ALTER TABLE orders DROP COLUMN IF EXISTS import_row;
+ALTER TABLE orders DROP COLUMN IF EXISTS import_job_id;
+
+DROP TABLE IF EXISTS import_jobs;
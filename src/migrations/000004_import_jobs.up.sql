@@ -0,0 +1,26 @@
+CREATE TABLE import_jobs (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL REFERENCES tenants(id),
+    file_key TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    rows_read INT NOT NULL DEFAULT 0,
+    rows_imported INT NOT NULL DEFAULT 0,
+    rows_failed INT NOT NULL DEFAULT 0,
+    checkpoint_row INT NOT NULL DEFAULT 1,
+    error TEXT,
+    locked_by TEXT,
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_import_jobs_status_created ON import_jobs(status, created_at);
+
+-- Rows imported by a job are stamped with it, which makes re-running a
+-- chunk after a crash idempotent.
+ALTER TABLE orders ADD COLUMN import_job_id UUID;
+ALTER TABLE orders ADD COLUMN import_row INT;
+CREATE UNIQUE INDEX idx_orders_import_row ON orders(import_job_id, import_row);
@@ -0,0 +1,2 @@
+-- Set when a cancelled job's orders were (or are still being) removed.
+ALTER TABLE import_jobs ADD COLUMN rolled_back BOOLEAN NOT NULL DEFAULT FALSE;
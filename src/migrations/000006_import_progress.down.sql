@@ -0,0 +1,3 @@
+ALTER TABLE import_jobs DROP COLUMN IF EXISTS error_samples;
+ALTER TABLE import_jobs DROP COLUMN IF EXISTS batches_flushed;
+ALTER TABLE import_jobs DROP COLUMN IF EXISTS rows_geocoded;
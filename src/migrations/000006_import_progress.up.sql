@@ -0,0 +1,3 @@
+ALTER TABLE import_jobs ADD COLUMN rows_geocoded INT NOT NULL DEFAULT 0;
+ALTER TABLE import_jobs ADD COLUMN batches_flushed INT NOT NULL DEFAULT 0;
+ALTER TABLE import_jobs ADD COLUMN error_samples JSONB NOT NULL DEFAULT '[]';
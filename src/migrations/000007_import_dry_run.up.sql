@@ -0,0 +1,2 @@
+ALTER TABLE import_jobs ADD COLUMN dry_run BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE import_jobs ADD COLUMN report JSONB;
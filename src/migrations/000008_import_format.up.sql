@@ -0,0 +1,2 @@
+-- Declared by the upload's content type; empty means detect from the file.
+ALTER TABLE import_jobs ADD COLUMN format VARCHAR(16) NOT NULL DEFAULT '';
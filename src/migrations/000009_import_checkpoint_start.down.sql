@@ -0,0 +1 @@
+ALTER TABLE import_jobs ALTER COLUMN checkpoint_row SET DEFAULT 1;
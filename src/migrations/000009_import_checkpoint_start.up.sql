@@ -0,0 +1,5 @@
+-- New jobs start at checkpoint 0. JSON and NDJSON rows are numbered from
+-- 1, so the old default skipped their first row; CSV and XLSX readers skip
+-- their own header rows.
+ALTER TABLE import_jobs ALTER COLUMN checkpoint_row SET DEFAULT 0;
+UPDATE import_jobs SET checkpoint_row = 0 WHERE checkpoint_row = 1 AND rows_read = 0;
@@ -0,0 +1,3 @@
+-- A queued job postponed until the geocoding quota resets is not claimed
+-- before resume_at.
+ALTER TABLE import_jobs ADD COLUMN resume_at TIMESTAMPTZ;
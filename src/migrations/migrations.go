@@ -0,0 +1,14 @@
+// Package migrations holds the database schema migrations and the tax
+// rates seeded into it. Both are embedded, so the binary runs from any
+// working directory.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var Schema embed.FS
+
+// TaxRates is the published rate table, a CSV file with a header row.
+//
+//go:embed tax_rates.csv
+var TaxRates []byte
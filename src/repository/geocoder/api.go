@@ -2,14 +2,23 @@ package geocoder
 
 import (
 	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/metrics"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 const (
 	url = "https://geocode.googleapis.com/v4beta/geocode/location"
+
+	// provider labels the metrics of this geocoder.
+	provider = "google"
 )
 
 type GeocodingResponse struct {
@@ -25,6 +34,11 @@ type AddressComponent struct {
 	Types    []string `json:"types"`
 }
 
+const (
+	pingLatitude  = 40.758
+	pingLongitude = -73.9855
+)
+
 type Api struct {
 	key    string
 	client *http.Client
@@ -33,19 +47,55 @@ type Api struct {
 func NewApi(key string) *Api {
 	return &Api{
 		key:    key,
-		client: http.DefaultClient,
+		client: &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
+	}
+}
+
+func (a *Api) GetJurisdiction(ctx context.Context, latitude, longitude float64) (*entity.Jurisdiction, error) {
+	start := time.Now()
+	jurisdiction, err := a.lookup(ctx, latitude, longitude)
+	metrics.GeocodingDuration.WithLabelValues(provider).Observe(time.Since(start).Seconds())
+
+	result := "ok"
+	switch {
+	case errors.Is(err, entity.ErrNotFound):
+		result = "not_found"
+	case err != nil:
+		result = "error"
 	}
+	metrics.GeocodingRequests.WithLabelValues(provider, result).Inc()
+
+	return jurisdiction, err
+}
+
+// Ping checks that the geocoding API answers with the configured key by
+// resolving a fixed point in Manhattan.
+func (a *Api) Ping(ctx context.Context) error {
+	_, err := a.GetJurisdiction(ctx, pingLatitude, pingLongitude)
+	return err
 }
 
-func (a *Api) GetJurisdiction(latitude, longitude float64) (*entity.Jurisdiction, error) {
-	response, err := a.client.Get(
-		fmt.Sprintf("%s?location.latitude=%f&location.longitude=%f&key=%s", url, latitude, longitude, a.key),
-	)
+func (a *Api) lookup(ctx context.Context, latitude, longitude float64) (*entity.Jurisdiction, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s?location.latitude=%f&location.longitude=%f", url, latitude, longitude),
+		nil)
+	if err != nil {
+		return nil, err
+	}
+	// The key goes in a header so that it stays out of the URLs recorded
+	// in traces.
+	request.Header.Set("X-Goog-Api-Key", a.key)
+
+	response, err := a.client.Do(request)
 	if err != nil {
 		return nil, err
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geocoding request failed with status %d", response.StatusCode)
+	}
+
 	var geocodingResponse GeocodingResponse
 	if err := json.NewDecoder(response.Body).Decode(&geocodingResponse); err != nil {
 		return nil, err
@@ -61,7 +111,7 @@ func (a *Api) GetJurisdiction(latitude, longitude float64) (*entity.Jurisdiction
 
 func (a *Api) extractJurisdiction(results []Result) (string, string, string, error) {
 	if len(results) == 0 {
-		return "", "", "", fmt.Errorf("no results found")
+		return "", "", "", fmt.Errorf("no results found: %w", entity.ErrNotFound)
 	}
 
 	var state, city, county string
@@ -0,0 +1,115 @@
+// Package query filters, sorts and aggregates orders in Go, for the
+// repositories whose storage cannot do it itself.
+package query
+
+import (
+	"InstantWellnessKits/src/entity"
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Matches applies the tenant, jurisdiction and time filters of params.
+func Matches(order *entity.Order, params entity.ListParams) bool {
+	switch {
+	case order.TenantId != params.TenantId:
+		return false
+	case params.State != "" && order.Jurisdiction.State != params.State:
+		return false
+	case params.County != "" && order.Jurisdiction.County != params.County:
+		return false
+	case params.City != "" && order.Jurisdiction.City != params.City:
+		return false
+	case params.From != nil && order.Timestamp.Before(*params.From):
+		return false
+	case params.To != nil && order.Timestamp.After(*params.To):
+		return false
+	}
+	return true
+}
+
+// SortNewestFirst orders by timestamp, newest first, breaking ties by id
+// so that pages are stable.
+func SortNewestFirst(orders []*entity.Order) {
+	slices.SortFunc(orders, func(a, b *entity.Order) int {
+		if c := b.Timestamp.Compare(a.Timestamp); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Id.String(), b.Id.String())
+	})
+}
+
+// Timeseries aggregates the orders of params' time range into buckets
+// truncated in params.Location, ordered by bucket and county.
+func Timeseries(orders []*entity.Order, params entity.TimeseriesParams) []*entity.TimeseriesPoint {
+	type key struct {
+		bucket time.Time
+		county string
+	}
+	points := make(map[key]*entity.TimeseriesPoint)
+	for _, order := range orders {
+		if order.Timestamp.Before(params.From) || !order.Timestamp.Before(params.To) {
+			continue
+		}
+		k := key{bucket: entity.TruncateToInterval(order.Timestamp, params.Interval, params.Location)}
+		if params.GroupByCounty {
+			k.county = order.Jurisdiction.County
+		}
+
+		point, ok := points[k]
+		if !ok {
+			point = &entity.TimeseriesPoint{Bucket: k.bucket, County: k.county,
+				Subtotal: decimal.Zero, Tax: decimal.Zero, Total: decimal.Zero}
+			points[k] = point
+		}
+		point.Orders++
+		point.Subtotal = point.Subtotal.Add(order.Subtotal)
+		point.Tax = point.Tax.Add(order.TaxAmount)
+		point.Total = point.Total.Add(order.TotalAmount)
+	}
+
+	result := make([]*entity.TimeseriesPoint, 0, len(points))
+	for _, point := range points {
+		result = append(result, point)
+	}
+	slices.SortFunc(result, func(a, b *entity.TimeseriesPoint) int {
+		if c := a.Bucket.Compare(b.Bucket); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.County, b.County)
+	})
+	return result
+}
+
+// Heatmap bins orders into the cells of grid, ordered by row and column.
+func Heatmap(orders []*entity.Order, grid entity.Grid) []*entity.HeatmapCell {
+	type key struct{ row, column int64 }
+	cells := make(map[key]*entity.HeatmapCell)
+	for _, order := range orders {
+		row, column := grid.Cell(order.Latitude, order.Longitude)
+		cell, ok := cells[key{row, column}]
+		if !ok {
+			cell = &entity.HeatmapCell{Row: row, Column: column,
+				Subtotal: decimal.Zero, Tax: decimal.Zero, Total: decimal.Zero}
+			cells[key{row, column}] = cell
+		}
+		cell.Orders++
+		cell.Subtotal = cell.Subtotal.Add(order.Subtotal)
+		cell.Tax = cell.Tax.Add(order.TaxAmount)
+		cell.Total = cell.Total.Add(order.TotalAmount)
+	}
+
+	result := make([]*entity.HeatmapCell, 0, len(cells))
+	for _, cell := range cells {
+		result = append(result, cell)
+	}
+	slices.SortFunc(result, func(a, b *entity.HeatmapCell) int {
+		if c := cmp.Compare(a.Row, b.Row); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Column, b.Column)
+	})
+	return result
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type storedKey struct {
+	key  entity.APIKey
+	hash string
+}
+
+// APIKeys stores keys for the life of the process, so keys can only be
+// issued through code. That is why STORAGE=memory turns authentication off
+// unless AUTH_ENABLED is set.
+type APIKeys struct {
+	tenants Tenants
+
+	mu   sync.RWMutex
+	keys []*storedKey
+}
+
+// Tenants registers the tenant of a new key, so that its tax settings can
+// be read and saved.
+type Tenants interface {
+	AddTenant(ctx context.Context, tenantId string) error
+}
+
+// NewAPIKeys registers the tenant of every new key with tenants.
+func NewAPIKeys(tenants Tenants) *APIKeys {
+	return &APIKeys{tenants: tenants}
+}
+
+func (r *APIKeys) Create(ctx context.Context, key *entity.APIKey, hash string) error {
+	if err := r.tenants.AddTenant(ctx, key.TenantId); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := *key
+	c.Roles = slices.Clone(key.Roles)
+	r.keys = append(r.keys, &storedKey{key: c, hash: hash})
+	return nil
+}
+
+func (r *APIKeys) FindActiveByHash(_ context.Context, hash string) (*entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, stored := range r.keys {
+		if stored.hash == hash && stored.key.RevokedAt == nil {
+			key := stored.key
+			return &key, nil
+		}
+	}
+	return nil, entity.ErrNotFound
+}
+
+func (r *APIKeys) List(_ context.Context) ([]*entity.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*entity.APIKey, len(r.keys))
+	for i, stored := range r.keys {
+		key := stored.key
+		keys[i] = &key
+	}
+	return keys, nil
+}
+
+func (r *APIKeys) Revoke(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.keys {
+		if stored.key.Id == id && stored.key.RevokedAt == nil {
+			now := time.Now()
+			stored.key.RevokedAt = &now
+			return nil
+		}
+	}
+	return entity.ErrNotFound
+}
@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type storedJob struct {
+	job         *entity.ImportJob
+	lockedBy    string
+	lockedUntil time.Time
+}
+
+// ImportJobs is a queue of import jobs with the lease semantics of the
+// Postgres repository, for runners within one process.
+type ImportJobs struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]*storedJob
+}
+
+func NewImportJobs() *ImportJobs {
+	return &ImportJobs{jobs: make(map[uuid.UUID]*storedJob)}
+}
+
+func (r *ImportJobs) Create(_ context.Context, job *entity.ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jobs[job.Id] = &storedJob{job: copyJob(job)}
+	return nil
+}
+
+func (r *ImportJobs) Get(_ context.Context, tenantId string, id uuid.UUID) (*entity.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.jobs[id]
+	if !ok || stored.job.TenantId != tenantId {
+		return nil, entity.ErrNotFound
+	}
+	return copyJob(stored.job), nil
+}
+
+// Claim leases the oldest queued job that is not postponed, or a running
+// job whose lease expired, to workerId.
+func (r *ImportJobs) Claim(_ context.Context, workerId string, lease time.Duration) (*entity.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var next *storedJob
+	for _, stored := range r.jobs {
+		queued := stored.job.Status == entity.ImportQueued &&
+			(stored.job.ResumeAt == nil || !now.Before(*stored.job.ResumeAt))
+		claimable := queued || (stored.job.Status == entity.ImportRunning && stored.lockedUntil.Before(now))
+		if claimable && (next == nil || stored.job.CreatedAt.Before(next.job.CreatedAt)) {
+			next = stored
+		}
+	}
+	if next == nil {
+		return nil, entity.ErrNotFound
+	}
+
+	next.job.Status = entity.ImportRunning
+	next.job.ResumeAt, next.job.Error = nil, ""
+	next.lockedBy = workerId
+	next.lockedUntil = now.Add(lease)
+	if next.job.StartedAt == nil {
+		next.job.StartedAt = &now
+	}
+	next.job.UpdatedAt = now
+	return copyJob(next.job), nil
+}
+
+func (r *ImportJobs) Checkpoint(_ context.Context, id uuid.UUID, workerId string,
+	progress entity.ImportProgress, lease time.Duration) error {
+	return r.update(id, workerId, func(stored *storedJob, now time.Time) {
+		stored.job = stored.job.WithProgress(progress)
+		stored.lockedUntil = now.Add(lease)
+		stored.job.UpdatedAt = now
+	})
+}
+
+func (r *ImportJobs) Heartbeat(_ context.Context, id uuid.UUID, workerId string, lease time.Duration) error {
+	return r.update(id, workerId, func(stored *storedJob, now time.Time) {
+		stored.lockedUntil = now.Add(lease)
+	})
+}
+
+func (r *ImportJobs) Finish(_ context.Context, id uuid.UUID, workerId string,
+	status entity.ImportStatus, message string) error {
+	return r.update(id, workerId, func(stored *storedJob, now time.Time) {
+		stored.job.Status = status
+		stored.job.Error = message
+		stored.lockedBy, stored.lockedUntil = "", time.Time{}
+		stored.job.FinishedAt = &now
+		stored.job.UpdatedAt = now
+	})
+}
+
+// Release hands a running job back to the queue.
+func (r *ImportJobs) Release(_ context.Context, id uuid.UUID, workerId string) error {
+	return r.update(id, workerId, func(stored *storedJob, now time.Time) {
+		stored.job.Status = entity.ImportQueued
+		stored.lockedBy, stored.lockedUntil = "", time.Time{}
+		stored.job.UpdatedAt = now
+	})
+}
+
+// Postpone hands a running job back to the queue, to be claimed no sooner
+// than until. message tells why.
+func (r *ImportJobs) Postpone(_ context.Context, id uuid.UUID, workerId string,
+	until time.Time, message string) error {
+	return r.update(id, workerId, func(stored *storedJob, now time.Time) {
+		stored.job.Status = entity.ImportQueued
+		stored.job.ResumeAt = &until
+		stored.job.Error = message
+		stored.lockedBy, stored.lockedUntil = "", time.Time{}
+		stored.job.UpdatedAt = now
+	})
+}
+
+// Transition moves a job of tenantId from one of the from statuses to
+// status and revokes its lease. It returns entity.ErrNotFound when no job
+// in one of the from statuses matched.
+func (r *ImportJobs) Transition(_ context.Context, tenantId string, id uuid.UUID,
+	from []entity.ImportStatus, status entity.ImportStatus, rollback bool) (*entity.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.jobs[id]
+	if !ok || stored.job.TenantId != tenantId || !slices.Contains(from, stored.job.Status) {
+		return nil, entity.ErrNotFound
+	}
+
+	now := time.Now()
+	stored.job.Status = status
+	stored.job.RolledBack = stored.job.RolledBack || rollback
+	stored.lockedBy, stored.lockedUntil = "", time.Time{}
+	if status == entity.ImportCancelled {
+		stored.job.FinishedAt = &now
+	}
+	stored.job.UpdatedAt = now
+	return copyJob(stored.job), nil
+}
+
+// update applies fn to a running job still leased to workerId.
+func (r *ImportJobs) update(id uuid.UUID, workerId string, fn func(stored *storedJob, now time.Time)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.jobs[id]
+	if !ok || stored.lockedBy != workerId || stored.job.Status != entity.ImportRunning {
+		return entity.ErrLeaseLost
+	}
+	fn(stored, time.Now())
+	return nil
+}
+
+func copyJob(job *entity.ImportJob) *entity.ImportJob {
+	c := job.WithProgress(job.Progress())
+	if c.ErrorSamples == nil {
+		c.ErrorSamples = make([]entity.ImportRowError, 0)
+	}
+	if c.Report == nil {
+		c.Report = entity.NewImportReport()
+	}
+	return c
+}
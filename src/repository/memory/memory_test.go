@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/repository/repotest"
+	"InstantWellnessKits/src/usecase"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+const tenant = "acme"
+
+func newTaxRates(t *testing.T) *TaxRates {
+	t.Helper()
+	rates, err := NewTaxRates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rates
+}
+
+func TestOrders(t *testing.T) {
+	repotest.RunOrders(t, func(*testing.T) (usecase.Orders, string) {
+		return NewOrders(), tenant
+	})
+}
+
+func TestTaxRates(t *testing.T) {
+	repotest.RunTaxRates(t, func(t *testing.T) (repotest.TaxRates, string) {
+		rates := newTaxRates(t)
+		if err := rates.AddTenant(context.Background(), tenant); err != nil {
+			t.Fatal(err)
+		}
+		return rates, tenant
+	})
+}
+
+func TestAPIKeys(t *testing.T) {
+	repotest.RunAPIKeys(t, func(t *testing.T) (usecase.APIKeys, string) {
+		return NewAPIKeys(newTaxRates(t)), tenant
+	})
+}
+
+func TestImportJobsPostpone(t *testing.T) {
+	ctx := context.Background()
+	jobs := NewImportJobs()
+	job := entity.NewImportJob(tenant, "orders.csv", "", false)
+	if err := jobs.Create(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jobs.Claim(ctx, "a", time.Minute); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+
+	until := time.Now().Add(50 * time.Millisecond)
+	if err := jobs.Postpone(ctx, job.Id, "a", until, "quota exhausted"); err != nil {
+		t.Fatalf("Postpone: %v", err)
+	}
+	if _, err := jobs.Claim(ctx, "b", time.Minute); !errors.Is(err, entity.ErrNotFound) {
+		t.Fatalf("Claim before the job resumes = %v, want %v", err, entity.ErrNotFound)
+	}
+
+	time.Sleep(time.Until(until))
+	claimed, err := jobs.Claim(ctx, "b", time.Minute)
+	if err != nil {
+		t.Fatalf("Claim once the job resumes: %v", err)
+	}
+	if claimed.Status != entity.ImportRunning || claimed.ResumeAt != nil || claimed.Error != "" {
+		t.Errorf("claimed job = %+v, want it running with no resume time or error", claimed)
+	}
+}
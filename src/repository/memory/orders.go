@@ -0,0 +1,215 @@
+// Package memory keeps repositories in process memory, for running the
+// service and its tests without a database. Nothing survives a restart.
+package memory
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/repository/internal/query"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type importRow struct {
+	jobId uuid.UUID
+	row   int
+}
+
+// Orders stores orders the way the Postgres repository does: amounts are
+// rounded to their column scale, and a row an import job already inserted
+// is skipped.
+type Orders struct {
+	mu       sync.RWMutex
+	orders   map[uuid.UUID]*entity.Order
+	imported map[importRow]bool
+}
+
+func NewOrders() *Orders {
+	return &Orders{
+		orders:   make(map[uuid.UUID]*entity.Order),
+		imported: make(map[importRow]bool),
+	}
+}
+
+func (r *Orders) Create(ctx context.Context, order *entity.Order) (*entity.Order, error) {
+	if err := r.CreateBatch(ctx, []*entity.Order{order}); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// CreateBatch inserts all of orders or, if one is refused, none of them.
+func (r *Orders) CreateBatch(_ context.Context, orders []*entity.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	for _, order := range orders {
+		if order.TenantId != orders[0].TenantId {
+			return entity.ErrMixedTenants
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Rows an import job has already inserted are skipped before the ids
+	// are checked, as a retried batch carries the same ids.
+	fresh := make([]*entity.Order, 0, len(orders))
+	for _, order := range orders {
+		if order.ImportJobId != nil && r.imported[importRow{jobId: *order.ImportJobId, row: order.ImportRow}] {
+			continue
+		}
+		if _, ok := r.orders[order.Id]; ok {
+			return fmt.Errorf("%w: duplicate order id %s", entity.ErrRejected, order.Id)
+		}
+		fresh = append(fresh, order)
+	}
+	for _, order := range fresh {
+		if order.ImportJobId != nil {
+			r.imported[importRow{jobId: *order.ImportJobId, row: order.ImportRow}] = true
+		}
+		r.orders[order.Id] = stored(order)
+	}
+	return nil
+}
+
+func (r *Orders) List(_ context.Context, params entity.ListParams) (*entity.ListResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := &entity.ListResult{
+		GlobalTax:    decimal.Zero,
+		GlobalGrand:  decimal.Zero,
+		Last24hTax:   decimal.Zero,
+		Last24hGrand: decimal.Zero,
+	}
+	matched := make([]*entity.Order, 0)
+	for _, order := range r.orders {
+		if order.TenantId != params.TenantId {
+			continue
+		}
+		result.GlobalOrders++
+		result.GlobalTax = result.GlobalTax.Add(order.TaxAmount)
+		result.GlobalGrand = result.GlobalGrand.Add(order.TotalAmount)
+		if !order.Timestamp.Before(params.Last24hFrom) {
+			result.Last24hOrders++
+			result.Last24hTax = result.Last24hTax.Add(order.TaxAmount)
+			result.Last24hGrand = result.Last24hGrand.Add(order.TotalAmount)
+		}
+		if query.Matches(order, params) {
+			matched = append(matched, order)
+		}
+	}
+	query.SortNewestFirst(matched)
+	result.Total = len(matched)
+
+	if params.Limit <= 0 {
+		params.Limit = 20
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	offset := min((params.Page-1)*params.Limit, len(matched))
+	page := matched[offset:min(offset+params.Limit, len(matched))]
+
+	result.Orders = make([]*entity.Order, len(page))
+	for i, order := range page {
+		result.Orders[i] = clone(order)
+	}
+	return result, nil
+}
+
+// Stream calls fn for a snapshot of the matching orders, so fn may write
+// to the repository.
+func (r *Orders) Stream(ctx context.Context, params entity.ListParams, fn func(*entity.Order) error) error {
+	for _, order := range r.matching(params) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Orders) UpdateTaxes(_ context.Context, tenantId string, orders []*entity.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, order := range orders {
+		current, ok := r.orders[order.Id]
+		if !ok || current.TenantId != tenantId {
+			continue
+		}
+		current.CompositeTaxRate = order.CompositeTaxRate.Round(5)
+		current.TaxAmount = order.TaxAmount.Round(2)
+		current.TotalAmount = order.TotalAmount.Round(2)
+		current.Breakdown = order.Breakdown
+	}
+	return nil
+}
+
+func (r *Orders) DeleteByImport(_ context.Context, tenantId string, importJobId uuid.UUID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, order := range r.orders {
+		if order.TenantId != tenantId || order.ImportJobId == nil || *order.ImportJobId != importJobId {
+			continue
+		}
+		delete(r.orders, id)
+		delete(r.imported, importRow{jobId: importJobId, row: order.ImportRow})
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (r *Orders) Timeseries(_ context.Context, params entity.TimeseriesParams) ([]*entity.TimeseriesPoint, error) {
+	filter := entity.ListParams{TenantId: params.TenantId}
+	orders := r.matching(filter)
+	return query.Timeseries(orders, params), nil
+}
+
+func (r *Orders) Heatmap(_ context.Context, filter entity.ListParams, grid entity.Grid) ([]*entity.HeatmapCell, error) {
+	return query.Heatmap(r.matching(filter), grid), nil
+}
+
+// matching returns copies of the orders matching params, newest first.
+func (r *Orders) matching(params entity.ListParams) []*entity.Order {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]*entity.Order, 0)
+	for _, order := range r.orders {
+		if query.Matches(order, params) {
+			matched = append(matched, clone(order))
+		}
+	}
+	query.SortNewestFirst(matched)
+	return matched
+}
+
+// stored copies order as the database would keep it.
+func stored(order *entity.Order) *entity.Order {
+	c := clone(order)
+	c.Subtotal = c.Subtotal.Round(2)
+	c.CompositeTaxRate = c.CompositeTaxRate.Round(5)
+	c.TaxAmount = c.TaxAmount.Round(2)
+	c.TotalAmount = c.TotalAmount.Round(2)
+	return c
+}
+
+// clone copies order, so that callers cannot change what is stored.
+func clone(order *entity.Order) *entity.Order {
+	c := *order
+	if order.ImportJobId != nil {
+		id := *order.ImportJobId
+		c.ImportJobId = &id
+	}
+	return &c
+}
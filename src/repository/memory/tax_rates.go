@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/metrics"
+	"InstantWellnessKits/src/migrations"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+type rate struct {
+	composite decimal.Decimal
+	breakdown entity.TaxBreakdown
+}
+
+// TaxRates resolves rates from the embedded published table, with the
+// same fallbacks, exemptions and tenant overrides as the Postgres
+// repository. Only the default tenant exists until an API key is issued
+// for another.
+type TaxRates struct {
+	published map[string]rate
+
+	mu       sync.RWMutex
+	settings map[string]*entity.TaxSettings
+}
+
+func NewTaxRates() (*TaxRates, error) {
+	reader := csv.NewReader(bytes.NewReader(migrations.TaxRates))
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tax rates: %w", err)
+	}
+
+	published := make(map[string]rate, len(records))
+	for _, record := range records[1:] {
+		values := make([]decimal.Decimal, 5)
+		for i := range values {
+			if values[i], err = decimal.NewFromString(record[2+i]); err != nil {
+				return nil, fmt.Errorf("failed to read tax rate %v: %w", record, err)
+			}
+		}
+		// Names shared by a city and a county resolve to the first row,
+		// as the published table is looked up by name only.
+		if _, ok := published[record[1]]; ok {
+			continue
+		}
+		published[record[1]] = rate{
+			composite: values[0],
+			breakdown: *entity.NewTaxBreakdown(values[1], values[2], values[3], values[4]),
+		}
+	}
+
+	return &TaxRates{
+		published: published,
+		settings: map[string]*entity.TaxSettings{
+			entity.DefaultTenant: {Overrides: make([]entity.RateOverride, 0)},
+		},
+	}, nil
+}
+
+// AddTenant registers tenantId, if it is new, with no exemption or
+// overrides.
+func (r *TaxRates) AddTenant(_ context.Context, tenantId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.settings[tenantId]; !ok {
+		r.settings[tenantId] = &entity.TaxSettings{Overrides: make([]entity.RateOverride, 0)}
+	}
+	return nil
+}
+
+func (r *TaxRates) Get(_ context.Context, tenantId string, jurisdiction *entity.Jurisdiction) (decimal.Decimal,
+	*entity.TaxBreakdown, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	settings := r.settings[tenantId]
+	if settings != nil && settings.Exempt {
+		metrics.TaxRateResolutions.WithLabelValues("exempt").Inc()
+		return decimal.Zero, entity.NewTaxBreakdown(decimal.Zero, decimal.Zero,
+			decimal.Zero, decimal.Zero), nil
+	}
+
+	for _, level := range []struct{ label, name string }{
+		{"city", jurisdiction.City},
+		{"county", jurisdiction.County},
+		{"state", "New York State"},
+	} {
+		if found, ok := r.find(settings, level.name); ok {
+			metrics.TaxRateResolutions.WithLabelValues(level.label).Inc()
+			breakdown := found.breakdown
+			return found.composite, &breakdown, nil
+		}
+	}
+	return decimal.Zero, nil, fmt.Errorf("tax rate for %q: %w", "New York State", entity.ErrNotFound)
+}
+
+// find prefers the tenant's override of a jurisdiction to its published
+// rate.
+func (r *TaxRates) find(settings *entity.TaxSettings, name string) (rate, bool) {
+	if settings != nil {
+		for _, o := range settings.Overrides {
+			if o.JurisdictionName == name {
+				return rate{
+					composite: o.CompositeRate(),
+					breakdown: *entity.NewTaxBreakdown(o.StateRate, o.CountyRate, o.CityRate, o.SpecialRate),
+				}, true
+			}
+		}
+	}
+	found, ok := r.published[name]
+	return found, ok
+}
+
+func (r *TaxRates) GetSettings(_ context.Context, tenantId string) (*entity.TaxSettings, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	settings, ok := r.settings[tenantId]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+	return &entity.TaxSettings{Exempt: settings.Exempt, Overrides: slices.Clone(settings.Overrides)}, nil
+}
+
+// SaveSettings replaces the tenant's exemption flag and its full set of
+// rate overrides.
+func (r *TaxRates) SaveSettings(_ context.Context, tenantId string, settings *entity.TaxSettings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.settings[tenantId]; !ok {
+		return entity.ErrNotFound
+	}
+
+	overrides := slices.Clone(settings.Overrides)
+	slices.SortFunc(overrides, func(a, b entity.RateOverride) int {
+		return strings.Compare(a.JurisdictionName, b.JurisdictionName)
+	})
+	if overrides == nil {
+		overrides = make([]entity.RateOverride, 0)
+	}
+	r.settings[tenantId] = &entity.TaxSettings{Exempt: settings.Exempt, Overrides: overrides}
+	return nil
+}
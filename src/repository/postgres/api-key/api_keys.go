@@ -0,0 +1,132 @@
+package api_key
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	conn *sql.DB
+}
+
+func NewRepository(conn *sql.DB) *Repository {
+	return &Repository{conn: conn}
+}
+
+// Create stores key, registering its tenant first if it is new.
+func (r *Repository) Create(ctx context.Context, key *entity.APIKey, hash string) error {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO tenants (id, name) VALUES ($1, $1) ON CONFLICT (id) DO NOTHING", key.TenantId)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO api_keys (id, name, key_prefix, key_hash, roles, tenant_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	roles := make([]string, 0, len(key.Roles))
+	for _, role := range key.Roles {
+		roles = append(roles, string(role))
+	}
+
+	_, err = tx.ExecContext(ctx, query, key.Id, key.Name, key.Prefix,
+		hash, roles, key.TenantId, key.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindActiveByHash returns the non-revoked key with the given hash.
+func (r *Repository) FindActiveByHash(ctx context.Context, hash string) (*entity.APIKey, error) {
+	query := `
+		SELECT id, name, key_prefix, array_to_string(roles, ','), tenant_id, created_at, revoked_at
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`
+	key, err := scanAPIKey(r.conn.QueryRowContext(ctx, query, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+	return key, err
+}
+
+func (r *Repository) List(ctx context.Context) ([]*entity.APIKey, error) {
+	query := `
+		SELECT id, name, key_prefix, array_to_string(roles, ','), tenant_id, created_at, revoked_at
+		FROM api_keys
+		ORDER BY created_at
+	`
+	rows, err := r.conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*entity.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *Repository) Revoke(ctx context.Context, id uuid.UUID) error {
+	res, err := r.conn.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row scanner) (*entity.APIKey, error) {
+	var key entity.APIKey
+	var roles string
+	if err := row.Scan(&key.Id, &key.Name, &key.Prefix, &roles,
+		&key.TenantId, &key.CreatedAt, &key.RevokedAt); err != nil {
+		return nil, err
+	}
+
+	for _, role := range strings.Split(roles, ",") {
+		if role != "" {
+			key.Roles = append(key.Roles, entity.Role(role))
+		}
+	}
+
+	return &key, nil
+}
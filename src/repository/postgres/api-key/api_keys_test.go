@@ -0,0 +1,15 @@
+package api_key
+
+import (
+	"InstantWellnessKits/src/repository/repotest"
+	"InstantWellnessKits/src/usecase"
+	"testing"
+)
+
+func TestAPIKeys(t *testing.T) {
+	conn := repotest.OpenPostgres(t)
+	r := NewRepository(conn)
+	repotest.RunAPIKeys(t, func(t *testing.T) (usecase.APIKeys, string) {
+		return r, repotest.NewTenantId(t, conn)
+	})
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var ErrNoTaxRates = errors.New(`tax rate table is empty`)
+
+// Health probes the database for readiness checks.
+type Health struct {
+	conn            *sql.DB
+	expectedVersion uint
+}
+
+// NewHealth checks conn against the newest migration shipped with the
+// service.
+func NewHealth(conn *sql.DB) (*Health, error) {
+	version, err := LatestMigration()
+	if err != nil {
+		return nil, err
+	}
+	return &Health{conn: conn, expectedVersion: version}, nil
+}
+
+func (h *Health) Ping(ctx context.Context) error {
+	return h.conn.PingContext(ctx)
+}
+
+// CheckMigrations fails unless the schema is cleanly at the expected
+// version.
+func (h *Health) CheckMigrations(ctx context.Context) error {
+	version, dirty, err := schemaVersion(ctx, h.conn)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w at version %d", ErrMigrationDirty, version)
+	}
+	if version != h.expectedVersion {
+		return fmt.Errorf("%w: at %d, want %d", ErrMigrationVersion, version, h.expectedVersion)
+	}
+	return nil
+}
+
+func (h *Health) CheckTaxRates(ctx context.Context) error {
+	var populated bool
+	err := h.conn.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM tax_rates)").Scan(&populated)
+	if err != nil {
+		return err
+	}
+	if !populated {
+		return ErrNoTaxRates
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package import_job
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const selectColumns = `
+	id, tenant_id, file_key, file_name, format, status, dry_run, rows_read, rows_geocoded,
+	rows_imported, rows_failed, batches_flushed, checkpoint_row, error_samples, report,
+	COALESCE(error, ''), rolled_back, created_at, updated_at, started_at, finished_at, resume_at
+`
+
+type Repository struct {
+	conn *sql.DB
+}
+
+func NewRepository(conn *sql.DB) *Repository {
+	return &Repository{conn: conn}
+}
+
+func (r *Repository) Create(ctx context.Context, job *entity.ImportJob) error {
+	query := `
+		INSERT INTO import_jobs (id, tenant_id, file_key, file_name, format, status, dry_run,
+		                         checkpoint_row, report, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	reportJSON, err := json.Marshal(job.Report)
+	if err != nil {
+		return err
+	}
+	_, err = r.conn.ExecContext(ctx, query, job.Id, job.TenantId, job.FileKey, job.FileName,
+		job.Format, job.Status, job.DryRun, job.CheckpointRow, reportJSON, job.CreatedAt, job.UpdatedAt)
+	return err
+}
+
+func (r *Repository) Get(ctx context.Context, tenantId string, id uuid.UUID) (*entity.ImportJob, error) {
+	query := `SELECT ` + selectColumns + ` FROM import_jobs WHERE id = $1 AND tenant_id = $2`
+
+	job, err := scanJob(r.conn.QueryRowContext(ctx, query, id, tenantId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+	return job, err
+}
+
+// Claim takes the oldest queued job that is not postponed, or a running
+// job whose owner stopped renewing its lease, and leases it to workerId. Concurrent claimers skip
+// rows locked by each other, so every job has a single owner.
+func (r *Repository) Claim(ctx context.Context, workerId string, lease time.Duration) (*entity.ImportJob, error) {
+	query := `
+		UPDATE import_jobs
+		SET status = 'running', locked_by = $1, locked_until = NOW() + make_interval(secs => $2),
+		    started_at = COALESCE(started_at, NOW()), resume_at = NULL, error = NULL, updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM import_jobs
+			WHERE (status = 'queued' AND (resume_at IS NULL OR resume_at <= NOW()))
+			   OR (status = 'running' AND locked_until < NOW())
+			ORDER BY created_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING ` + selectColumns
+
+	job, err := scanJob(r.conn.QueryRowContext(ctx, query, workerId, lease.Seconds()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+	return job, err
+}
+
+// Checkpoint records progress and renews the lease in one statement.
+func (r *Repository) Checkpoint(ctx context.Context, id uuid.UUID, workerId string,
+	progress entity.ImportProgress, lease time.Duration) error {
+	query := `
+		UPDATE import_jobs
+		SET checkpoint_row = $3, rows_read = $4, rows_geocoded = $5, rows_imported = $6,
+		    rows_failed = $7, batches_flushed = $8, error_samples = $9, report = $10,
+		    locked_until = NOW() + make_interval(secs => $11), updated_at = NOW()
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'
+	`
+	samples := progress.ErrorSamples
+	if samples == nil {
+		samples = []entity.ImportRowError{}
+	}
+	samplesJSON, err := json.Marshal(samples)
+	if err != nil {
+		return err
+	}
+	reportJSON, err := json.Marshal(progress.Report)
+	if err != nil {
+		return err
+	}
+	return r.exec(ctx, query, id, workerId, progress.CheckpointRow, progress.RowsRead,
+		progress.RowsGeocoded, progress.RowsImported, progress.RowsFailed, progress.BatchesFlushed,
+		samplesJSON, reportJSON, lease.Seconds())
+}
+
+func (r *Repository) Heartbeat(ctx context.Context, id uuid.UUID, workerId string, lease time.Duration) error {
+	query := `
+		UPDATE import_jobs
+		SET locked_until = NOW() + make_interval(secs => $3)
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'
+	`
+	return r.exec(ctx, query, id, workerId, lease.Seconds())
+}
+
+func (r *Repository) Finish(ctx context.Context, id uuid.UUID, workerId string,
+	status entity.ImportStatus, message string) error {
+	query := `
+		UPDATE import_jobs
+		SET status = $3, error = NULLIF($4, ''), locked_by = NULL, locked_until = NULL,
+		    finished_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'
+	`
+	return r.exec(ctx, query, id, workerId, status, message)
+}
+
+// Release hands a running job back to the queue, so the next runner
+// resumes it right away instead of waiting for the lease to expire.
+func (r *Repository) Release(ctx context.Context, id uuid.UUID, workerId string) error {
+	query := `
+		UPDATE import_jobs
+		SET status = 'queued', locked_by = NULL, locked_until = NULL, updated_at = NOW()
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'
+	`
+	return r.exec(ctx, query, id, workerId)
+}
+
+// Postpone hands a running job back to the queue, to be claimed no sooner
+// than until. message tells why.
+func (r *Repository) Postpone(ctx context.Context, id uuid.UUID, workerId string,
+	until time.Time, message string) error {
+	query := `
+		UPDATE import_jobs
+		SET status = 'queued', resume_at = $3, error = $4, locked_by = NULL, locked_until = NULL,
+		    updated_at = NOW()
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'
+	`
+	return r.exec(ctx, query, id, workerId, until, message)
+}
+
+// Transition moves a job of tenantId from one of the from statuses to
+// status and revokes its lease, which stops the runner holding it at its
+// next heartbeat. It returns entity.ErrNotFound when no job in one of the
+// from statuses matched.
+func (r *Repository) Transition(ctx context.Context, tenantId string, id uuid.UUID,
+	from []entity.ImportStatus, status entity.ImportStatus, rollback bool) (*entity.ImportJob, error) {
+	statuses := make([]string, len(from))
+	for i, s := range from {
+		statuses[i] = string(s)
+	}
+
+	query := `
+		UPDATE import_jobs
+		SET status = $3, rolled_back = rolled_back OR $4, locked_by = NULL, locked_until = NULL,
+		    finished_at = CASE WHEN $3 = 'cancelled' THEN NOW() ELSE finished_at END,
+		    updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status = ANY(string_to_array($5, ','))
+		RETURNING ` + selectColumns
+
+	job, err := scanJob(r.conn.QueryRowContext(ctx, query, id, tenantId, status, rollback,
+		strings.Join(statuses, ",")))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+	return job, err
+}
+
+func (r *Repository) exec(ctx context.Context, query string, args ...any) error {
+	res, err := r.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return entity.ErrLeaseLost
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row scanner) (*entity.ImportJob, error) {
+	var job entity.ImportJob
+	var samplesJSON, reportJSON []byte
+	err := row.Scan(&job.Id, &job.TenantId, &job.FileKey, &job.FileName, &job.Format, &job.Status,
+		&job.DryRun, &job.RowsRead, &job.RowsGeocoded, &job.RowsImported, &job.RowsFailed,
+		&job.BatchesFlushed, &job.CheckpointRow, &samplesJSON, &reportJSON, &job.Error, &job.RolledBack, &job.CreatedAt,
+		&job.UpdatedAt, &job.StartedAt, &job.FinishedAt, &job.ResumeAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(samplesJSON, &job.ErrorSamples); err != nil {
+		return nil, err
+	}
+	// Jobs queued before reports existed have none.
+	job.Report = entity.NewImportReport()
+	if reportJSON != nil {
+		if err := json.Unmarshal(reportJSON, job.Report); err != nil {
+			return nil, err
+		}
+	}
+	return &job, nil
+}
@@ -0,0 +1,286 @@
+package postgres
+
+import (
+	"InstantWellnessKits/src/migrations"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// migrationLock is the advisory lock key held while migrating or seeding,
+// so that instances starting together take turns. Schema checks hold it
+// shared, so they wait for a migration in progress instead of seeing it
+// half done.
+const migrationLock int64 = 0x1A8E_7A11_0001
+
+var (
+	ErrMigrationDirty   = errors.New(`database migration left in a dirty state`)
+	ErrMigrationVersion = errors.New(`database schema is not at the expected migration`)
+)
+
+// withMigrationLock runs fn on a connection holding the migration lock.
+func withMigrationLock(ctx context.Context, conn *sql.DB, shared bool,
+	fn func(c *sql.Conn) error) error {
+	c, err := conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	lock, unlock := "SELECT pg_advisory_lock($1)", "SELECT pg_advisory_unlock($1)"
+	if shared {
+		lock, unlock = "SELECT pg_advisory_lock_shared($1)", "SELECT pg_advisory_unlock_shared($1)"
+	}
+	if _, err := c.ExecContext(ctx, lock, migrationLock); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	// The lock belongs to the session, so it must be released before the
+	// connection goes back to the pool.
+	defer func() {
+		if _, err := c.ExecContext(context.WithoutCancel(ctx), unlock, migrationLock); err != nil {
+			slog.ErrorContext(ctx, "Failed to release migration lock", "error", err)
+		}
+	}()
+
+	return fn(c)
+}
+
+// withMigrate runs fn with a migrator over the embedded migrations, under
+// the exclusive migration lock. It refuses to start from a dirty schema,
+// which only a person can repair; ForceMigration is the way out.
+func withMigrate(ctx context.Context, conn *sql.DB, allowDirty bool, fn func(m *migrate.Migrate) error) error {
+	return withMigrationLock(ctx, conn, false, func(c *sql.Conn) error {
+		if !allowDirty {
+			version, dirty, err := schemaVersion(ctx, c)
+			if err != nil {
+				return err
+			}
+			if dirty {
+				return dirtyError(version)
+			}
+		}
+
+		driver, err := postgres.WithConnection(ctx, c, &postgres.Config{})
+		if err != nil {
+			return err
+		}
+		schema, err := iofs.New(migrations.Schema, ".")
+		if err != nil {
+			return err
+		}
+		// The migrator is not closed: that would close c while it still
+		// holds the lock.
+		m, err := migrate.NewWithInstance("iofs", schema, "postgres", driver)
+		if err != nil {
+			return err
+		}
+		return fn(m)
+	})
+}
+
+func ApplyMigrations(ctx context.Context, conn *sql.DB) error {
+	return withMigrate(ctx, conn, false, func(m *migrate.Migrate) error {
+		err := m.Up()
+		if errors.Is(err, migrate.ErrNoChange) {
+			slog.InfoContext(ctx, "No database migrations to apply")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply database migrations: %w", err)
+		}
+		slog.InfoContext(ctx, "Database migrations applied")
+		return nil
+	})
+}
+
+// RevertMigrations rolls back the newest steps migrations.
+func RevertMigrations(ctx context.Context, conn *sql.DB, steps int) error {
+	return withMigrate(ctx, conn, false, func(m *migrate.Migrate) error {
+		if err := m.Steps(-steps); err != nil {
+			return fmt.Errorf("failed to revert database migrations: %w", err)
+		}
+		slog.InfoContext(ctx, "Database migrations reverted", "steps", steps)
+		return nil
+	})
+}
+
+// ForceMigration records the schema as cleanly at version without running
+// anything, once someone has repaired a failed migration by hand.
+func ForceMigration(ctx context.Context, conn *sql.DB, version int) error {
+	return withMigrate(ctx, conn, true, func(m *migrate.Migrate) error {
+		if err := m.Force(version); err != nil {
+			return fmt.Errorf("failed to force migration version: %w", err)
+		}
+		slog.InfoContext(ctx, "Database migration version forced", "version", version)
+		return nil
+	})
+}
+
+// MigrationVersion returns the migration the schema is at, 0 before the
+// first one, and whether that migration failed halfway.
+func MigrationVersion(ctx context.Context, conn *sql.DB) (uint, bool, error) {
+	var version uint
+	var dirty bool
+	err := withMigrationLock(ctx, conn, true, func(c *sql.Conn) error {
+		var err error
+		version, dirty, err = schemaVersion(ctx, c)
+		return err
+	})
+	return version, dirty, err
+}
+
+// CheckSchema fails unless the schema is cleanly at the newest embedded
+// migration, for instances that do not migrate on startup.
+func CheckSchema(ctx context.Context, conn *sql.DB) error {
+	latest, err := LatestMigration()
+	if err != nil {
+		return err
+	}
+	version, dirty, err := MigrationVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return dirtyError(version)
+	}
+	if version != latest {
+		return fmt.Errorf("%w: at %d, want %d", ErrMigrationVersion, version, latest)
+	}
+	return nil
+}
+
+func dirtyError(version uint) error {
+	return fmt.Errorf("%w at version %d; repair the schema, then run `migrate force <version>`",
+		ErrMigrationDirty, version)
+}
+
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// schemaVersion reads the table golang-migrate keeps its state in, which
+// does not exist before the first migration.
+func schemaVersion(ctx context.Context, q querier) (uint, bool, error) {
+	var exists bool
+	err := q.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists)
+	if err != nil || !exists {
+		return 0, false, err
+	}
+
+	var version uint
+	var dirty bool
+	err = q.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// LatestMigration returns the version of the newest embedded migration.
+func LatestMigration() (uint, error) {
+	schema, err := iofs.New(migrations.Schema, ".")
+	if err != nil {
+		return 0, err
+	}
+	defer schema.Close()
+
+	return lastVersion(schema)
+}
+
+func lastVersion(schema source.Driver) (uint, error) {
+	version, err := schema.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := schema.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// SeedTaxRates inserts the embedded published rates, leaving rates that
+// already exist untouched.
+func SeedTaxRates(ctx context.Context, db *sql.DB) error {
+	return withMigrationLock(ctx, db, false, func(*sql.Conn) error {
+		return seedTaxRates(ctx, db)
+	})
+}
+
+func seedTaxRates(ctx context.Context, db *sql.DB) error {
+	reader := csv.NewReader(bytes.NewReader(migrations.TaxRates))
+
+	if _, err := reader.Read(); err != nil {
+		return fmt.Errorf("failed to read csv headers: %w", err)
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to read csv records: %w", err)
+	}
+
+	query := `
+		INSERT INTO tax_rates (
+			jurisdiction_type, jurisdiction_name, composite_rate, 
+			state_rate, county_rate, city_rate, special_rate, special_name
+		) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (jurisdiction_type, jurisdiction_name) DO NOTHING;
+	`
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var insertedCount int
+	for _, row := range records {
+		var specialName *string
+		if row[7] != "" {
+			specialName = &row[7]
+		}
+
+		res, err := stmt.ExecContext(ctx, row[0], row[1], row[2],
+			row[3], row[4], row[5], row[6], specialName)
+		if err != nil {
+			return fmt.Errorf("failed to insert row %v: %w", row, err)
+		}
+
+		rowsAffected, _ := res.RowsAffected()
+		insertedCount += int(rowsAffected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if insertedCount > 0 {
+		slog.InfoContext(ctx, "Seeded tax rates", "inserted", insertedCount)
+	} else {
+		slog.InfoContext(ctx, "Tax rates are already up to date")
+	}
+
+	return nil
+}
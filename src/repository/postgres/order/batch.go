@@ -0,0 +1,237 @@
+package order
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/metrics"
+	"InstantWellnessKits/src/repository/postgres"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var orderColumns = []string{
+	"id", "latitude", "longitude", "subtotal", "composite_tax_rate", "tax_amount", "total_amount",
+	"breakdown", "jurisdictions", "timestamp", "tenant_id", "import_job_id", "import_row",
+}
+
+var errCopyUnsupported = errors.New(`connection does not support COPY`)
+
+var tracer = otel.Tracer("InstantWellnessKits/src/repository/postgres/order")
+
+// wrappedConn is a driver connection wrapped by instrumentation, such as
+// otelsql, that exposes the driver's own connection.
+type wrappedConn interface {
+	Raw() driver.Conn
+}
+
+// CreateBatch inserts orders of a single tenant in one transaction. Rows go
+// over the COPY protocol into a staging table and from there into orders,
+// skipping rows an import job has already inserted. Connections that are
+// not pgx fall back to a single multi-row INSERT.
+func (r *Repository) CreateBatch(ctx context.Context, orders []*entity.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	tenantId := orders[0].TenantId
+	for _, order := range orders {
+		if order.TenantId != tenantId {
+			return entity.ErrMixedTenants
+		}
+	}
+
+	conn, err := r.conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	start, method := time.Now(), "copy"
+	err = conn.Raw(func(driverConn any) error {
+		if wrapped, ok := driverConn.(wrappedConn); ok {
+			driverConn = wrapped.Raw()
+		}
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errCopyUnsupported
+		}
+		return copyOrders(ctx, pgxConn.Conn(), tenantId, orders)
+	})
+	if errors.Is(err, errCopyUnsupported) {
+		start, method = time.Now(), "insert"
+		err = r.insertOrders(ctx, tenantId, orders)
+	}
+	metrics.BatchInsertDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	return classifyWriteError(err)
+}
+
+// classifyWriteError marks data exceptions (SQLSTATE class 22) and
+// integrity constraint violations (class 23) as entity.ErrRejected: they
+// are caused by some row of the batch and retrying without it succeeds.
+func classifyWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")) {
+		return fmt.Errorf("%w: %s", entity.ErrRejected, pgErr.Message)
+	}
+	return err
+}
+
+// copyOrders runs on the raw pgx connection, which the SQL instrumentation
+// does not see, so it traces itself.
+func copyOrders(ctx context.Context, conn *pgx.Conn, tenantId string, orders []*entity.Order) (err error) {
+	ctx, span := tracer.Start(ctx, "orders.copy", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("db.rows", len(orders))))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	rows := make([][]any, len(orders))
+	for i, order := range orders {
+		row, err := copyRow(order)
+		if err != nil {
+			return err
+		}
+		rows[i] = row
+	}
+
+	// The transaction is driven by hand so that BEGIN rides with the setup
+	// and COMMIT with the INSERT: a batch takes three round trips however
+	// many rows it has.
+	defer func() {
+		if err != nil && conn.PgConn().TxStatus() != 'I' {
+			_, _ = conn.Exec(context.WithoutCancel(ctx), "ROLLBACK")
+		}
+	}()
+
+	// Drop to the tenant role (see postgres.BeginTenantTx) and create the
+	// staging table as that role, so it can write to it.
+	setup := &pgx.Batch{}
+	setup.Queue("BEGIN")
+	setup.Queue("SET LOCAL ROLE iwk_tenant")
+	setup.Queue("SELECT set_config('app.tenant_id', $1, true)", tenantId)
+	setup.Queue("CREATE TEMP TABLE orders_staging ON COMMIT DROP AS SELECT " +
+		strings.Join(orderColumns, ", ") + " FROM orders WITH NO DATA")
+	if err := conn.SendBatch(ctx, setup).Close(); err != nil {
+		return err
+	}
+
+	if _, err := conn.CopyFrom(ctx, pgx.Identifier{"orders_staging"}, orderColumns,
+		pgx.CopyFromRows(rows)); err != nil {
+		return err
+	}
+
+	finish := &pgx.Batch{}
+	finish.Queue("INSERT INTO orders (" + strings.Join(orderColumns, ", ") +
+		") SELECT * FROM orders_staging ON CONFLICT (import_job_id, import_row) DO NOTHING")
+	finish.Queue("COMMIT")
+	return conn.SendBatch(ctx, finish).Close()
+}
+
+func copyRow(order *entity.Order) ([]any, error) {
+	breakdownJSON, err := json.Marshal(order.Breakdown)
+	if err != nil {
+		return nil, err
+	}
+	jurisdictionJSON, err := json.Marshal(order.Jurisdiction)
+	if err != nil {
+		return nil, err
+	}
+
+	importJobId := pgtype.UUID{}
+	if order.ImportJobId != nil {
+		importJobId = pgtype.UUID{Bytes: *order.ImportJobId, Valid: true}
+	}
+
+	return []any{
+		pgtype.UUID{Bytes: order.Id, Valid: true}, order.Latitude, order.Longitude,
+		numeric(order.Subtotal), numeric(order.CompositeTaxRate), numeric(order.TaxAmount),
+		numeric(order.TotalAmount), breakdownJSON, jurisdictionJSON, order.Timestamp,
+		order.TenantId, importJobId, importRow(order),
+	}, nil
+}
+
+func numeric(d decimal.Decimal) pgtype.Numeric {
+	return pgtype.Numeric{Int: d.Coefficient(), Exp: d.Exponent(), Valid: true}
+}
+
+// insertOrders is the fallback for drivers without COPY: one INSERT with a
+// VALUES row per order.
+func (r *Repository) insertOrders(ctx context.Context, tenantId string, orders []*entity.Order) error {
+	tx, err := postgres.BeginTenantTx(ctx, r.conn, tenantId, false)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertValues(ctx, tx, orders); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// maxInsertParams is the most bind parameters Postgres allows in one
+// statement.
+const maxInsertParams = 65535
+
+func insertValues(ctx context.Context, tx *sql.Tx, orders []*entity.Order) error {
+	perStatement := maxInsertParams / len(orderColumns)
+	for start := 0; start < len(orders); start += perStatement {
+		batch := orders[start:min(start+perStatement, len(orders))]
+
+		var query strings.Builder
+		query.WriteString("INSERT INTO orders (" + strings.Join(orderColumns, ", ") + ") VALUES ")
+		args := make([]any, 0, len(batch)*len(orderColumns))
+		for i, order := range batch {
+			breakdownJSON, err := json.Marshal(order.Breakdown)
+			if err != nil {
+				return err
+			}
+			jurisdictionJSON, err := json.Marshal(order.Jurisdiction)
+			if err != nil {
+				return err
+			}
+
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString("(")
+			for c := range orderColumns {
+				if c > 0 {
+					query.WriteString(", ")
+				}
+				fmt.Fprintf(&query, "$%d", len(args)+c+1)
+			}
+			query.WriteString(")")
+
+			args = append(args, order.Id, order.Latitude, order.Longitude,
+				order.Subtotal, order.CompositeTaxRate, order.TaxAmount, order.TotalAmount,
+				breakdownJSON, jurisdictionJSON, order.Timestamp, order.TenantId,
+				order.ImportJobId, importRow(order))
+		}
+		query.WriteString(" ON CONFLICT (import_job_id, import_row) DO NOTHING")
+
+		if _, err := tx.ExecContext(ctx, query.String(), args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package order
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/repository/repotest"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const benchTenant = "bench"
+
+func benchOrders(n int) []*entity.Order {
+	orders := make([]*entity.Order, n)
+	for i := range orders {
+		order := entity.NewOrder(40.7128, -74.006, decimal.RequireFromString("19.99"),
+			decimal.RequireFromString("0.08875"), decimal.RequireFromString("1.77"), decimal.RequireFromString("21.76"),
+			entity.NewTaxBreakdown(decimal.RequireFromString("0.04"), decimal.RequireFromString("0.04875"),
+				decimal.Zero, decimal.Zero),
+			&entity.Jurisdiction{State: "New York", County: "New York County", City: "New York"},
+			time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+		order.TenantId = benchTenant
+		orders[i] = order
+	}
+	return orders
+}
+
+// BenchmarkCreateBatch compares COPY through a staging table with the
+// multi-row INSERT fallback and, as a baseline, with one Create per order,
+// at the import chunk size and around it. It needs a database; see
+// repotest.PostgresEnv.
+func BenchmarkCreateBatch(b *testing.B) {
+	conn := repotest.OpenPostgres(b)
+	repotest.AddTenant(b, conn, benchTenant)
+	b.Cleanup(func() {
+		_, _ = conn.Exec("DELETE FROM orders WHERE tenant_id = $1", benchTenant)
+	})
+	r := NewRepository(conn)
+	ctx := context.Background()
+
+	methods := []struct {
+		name   string
+		insert func([]*entity.Order) error
+	}{
+		{"copy", func(orders []*entity.Order) error { return r.CreateBatch(ctx, orders) }},
+		{"insert", func(orders []*entity.Order) error { return r.insertOrders(ctx, benchTenant, orders) }},
+		{"create", func(orders []*entity.Order) error {
+			for _, order := range orders {
+				if _, err := r.Create(ctx, order); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+	}
+	for _, size := range []int{50, 500, 5000} {
+		orders := benchOrders(size)
+		for _, method := range methods {
+			b.Run(fmt.Sprintf("%s/%d", method.name, size), func(b *testing.B) {
+				for b.Loop() {
+					for _, order := range orders {
+						order.Id = uuid.New()
+					}
+					if err := method.insert(orders); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(size*b.N)/b.Elapsed().Seconds(), "rows/s")
+			})
+		}
+	}
+}
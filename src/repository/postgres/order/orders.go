@@ -2,15 +2,19 @@ package order
 
 import (
 	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/repository/postgres"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
+const streamBatchSize = 1000
+
 type Repository struct {
 	conn *sql.DB
 }
@@ -21,11 +25,21 @@ func NewRepository(conn *sql.DB) *Repository {
 	}
 }
 
+// insertQuery skips rows an import job has already inserted, so a chunk
+// replayed after a crash does not duplicate orders.
+const insertQuery = `
+	INSERT INTO orders (id, latitude, longitude, subtotal, composite_tax_rate, tax_amount, total_amount, breakdown, jurisdictions, timestamp, tenant_id, import_job_id, import_row)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	ON CONFLICT (import_job_id, import_row) DO NOTHING
+`
+
 func (r *Repository) Create(ctx context.Context, order *entity.Order) (*entity.Order, error) {
-	query := `
-		INSERT INTO orders (id, latitude, longitude, subtotal, composite_tax_rate, tax_amount, total_amount, breakdown, jurisdictions, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-	`
+	tx, err := postgres.BeginTenantTx(ctx, r.conn, order.TenantId, false)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	breakdownJSON, err := json.Marshal(order.Breakdown)
 	if err != nil {
 		return nil, err
@@ -34,54 +48,33 @@ func (r *Repository) Create(ctx context.Context, order *entity.Order) (*entity.O
 	if err != nil {
 		return nil, err
 	}
-	_, err = r.conn.ExecContext(ctx, query, order.Id, order.Latitude, order.Longitude,
+	_, err = tx.ExecContext(ctx, insertQuery, order.Id, order.Latitude, order.Longitude,
 		order.Subtotal, order.CompositeTaxRate, order.TaxAmount, order.TotalAmount,
-		breakdownJSON, jurisdictionJSON, order.Timestamp)
+		breakdownJSON, jurisdictionJSON, order.Timestamp, order.TenantId,
+		order.ImportJobId, importRow(order))
 	if err != nil {
 		return nil, err
 	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	return order, nil
 }
 
 func (r *Repository) List(ctx context.Context, params entity.ListParams) (*entity.ListResult, error) {
-	var conditions []string
-	var args []interface{}
-	i := 1
-
-	if params.State != "" {
-		conditions = append(conditions, fmt.Sprintf("jurisdictions->>'state' = $%d", i))
-		args = append(args, params.State)
-		i++
-	}
-	if params.County != "" {
-		conditions = append(conditions, fmt.Sprintf("jurisdictions->>'county' = $%d", i))
-		args = append(args, params.County)
-		i++
-	}
-	if params.City != "" {
-		conditions = append(conditions, fmt.Sprintf("jurisdictions->>'city' = $%d", i))
-		args = append(args, params.City)
-		i++
-	}
-	if params.From != nil {
-		conditions = append(conditions, fmt.Sprintf("timestamp >= $%d", i))
-		args = append(args, *params.From)
-		i++
-	}
-	if params.To != nil {
-		conditions = append(conditions, fmt.Sprintf("timestamp <= $%d", i))
-		args = append(args, *params.To)
-		i++
+	tx, err := postgres.BeginTenantTx(ctx, r.conn, params.TenantId, true)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	where := ""
-	if len(conditions) > 0 {
-		where = "WHERE " + strings.Join(conditions, " AND ")
-	}
+	where, args := buildFilter(params)
+	i := len(args) + 1
 
 	var total int
-	if err := r.conn.QueryRowContext(ctx,
+	if err := tx.QueryRowContext(ctx,
 		fmt.Sprintf("SELECT COUNT(*) FROM orders %s", where), args...,
 	).Scan(&total); err != nil {
 		return nil, err
@@ -89,16 +82,18 @@ func (r *Repository) List(ctx context.Context, params entity.ListParams) (*entit
 
 	var globalOrders int
 	var globalTax, globalGrand decimal.Decimal
-	if err := r.conn.QueryRowContext(ctx,
-		"SELECT COUNT(*), COALESCE(SUM(tax_amount), 0), COALESCE(SUM(total_amount), 0) FROM orders",
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*), COALESCE(SUM(tax_amount), 0), COALESCE(SUM(total_amount), 0) FROM orders WHERE tenant_id = $1",
+		params.TenantId,
 	).Scan(&globalOrders, &globalTax, &globalGrand); err != nil {
 		return nil, err
 	}
 
 	var last24hOrders int
 	var last24hTax, last24hGrand decimal.Decimal
-	if err := r.conn.QueryRowContext(ctx,
-		"SELECT COUNT(*), COALESCE(SUM(tax_amount), 0), COALESCE(SUM(total_amount), 0) FROM orders WHERE timestamp >= NOW() - INTERVAL '24 hours'",
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*), COALESCE(SUM(tax_amount), 0), COALESCE(SUM(total_amount), 0) FROM orders WHERE tenant_id = $1 AND timestamp >= $2",
+		params.TenantId, params.Last24hFrom,
 	).Scan(&last24hOrders, &last24hTax, &last24hGrand); err != nil {
 		return nil, err
 	}
@@ -121,7 +116,7 @@ func (r *Repository) List(ctx context.Context, params entity.ListParams) (*entit
 	`, where, i, i+1)
 
 	dataArgs := append(args, params.Limit, offset)
-	rows, err := r.conn.QueryContext(ctx, query, dataArgs...)
+	rows, err := tx.QueryContext(ctx, query, dataArgs...)
 	if err != nil {
 		return nil, err
 	}
@@ -129,25 +124,11 @@ func (r *Repository) List(ctx context.Context, params entity.ListParams) (*entit
 
 	orders := make([]*entity.Order, 0)
 	for rows.Next() {
-		var order entity.Order
-		var breakdownData []byte
-		var jurisdictionsData []byte
-
-		err := rows.Scan(&order.Id, &order.Latitude, &order.Longitude, &order.Subtotal,
-			&order.CompositeTaxRate, &order.TaxAmount, &order.TotalAmount,
-			&breakdownData, &jurisdictionsData, &order.Timestamp)
+		order, err := scanOrder(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		if err := json.Unmarshal(breakdownData, &order.Breakdown); err != nil {
-			return nil, err
-		}
-		if err := json.Unmarshal(jurisdictionsData, &order.Jurisdiction); err != nil {
-			return nil, err
-		}
-
-		orders = append(orders, &order)
+		orders = append(orders, order)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -166,33 +147,278 @@ func (r *Repository) List(ctx context.Context, params entity.ListParams) (*entit
 	}, nil
 }
 
-func (r *Repository) CreateBatch(ctx context.Context, orders []*entity.Order) error {
-	tx, err := r.conn.BeginTx(ctx, nil)
+// DeleteByImport removes every order inserted by an import job.
+func (r *Repository) DeleteByImport(ctx context.Context, tenantId string, importJobId uuid.UUID) (int64, error) {
+	tx, err := postgres.BeginTenantTx(ctx, r.conn, tenantId, false)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM orders WHERE import_job_id = $1", importJobId)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, tx.Commit()
+}
+
+// UpdateTaxes overwrites the rate, breakdown, tax and total of orders in
+// one transaction.
+func (r *Repository) UpdateTaxes(ctx context.Context, tenantId string, orders []*entity.Order) error {
+	tx, err := postgres.BeginTenantTx(ctx, r.conn, tenantId, false)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	query := `
-		INSERT INTO orders (id, latitude, longitude, subtotal, composite_tax_rate, tax_amount, total_amount, breakdown, jurisdictions, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-	`
+	stmt, err := tx.PrepareContext(ctx, `
+		UPDATE orders
+		SET composite_tax_rate = $2, tax_amount = $3, total_amount = $4, breakdown = $5
+		WHERE id = $1
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
 	for _, order := range orders {
 		breakdownJSON, err := json.Marshal(order.Breakdown)
 		if err != nil {
 			return err
 		}
-		jurisdictionJSON, err := json.Marshal(order.Jurisdiction)
+		_, err = stmt.ExecContext(ctx, order.Id, order.CompositeTaxRate, order.TaxAmount,
+			order.TotalAmount, breakdownJSON)
 		if err != nil {
 			return err
 		}
-		_, err = tx.ExecContext(ctx, query, order.Id, order.Latitude, order.Longitude,
-			order.Subtotal, order.CompositeTaxRate, order.TaxAmount, order.TotalAmount,
-			breakdownJSON, jurisdictionJSON, order.Timestamp)
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) Stream(ctx context.Context, params entity.ListParams,
+	fn func(*entity.Order) error) error {
+	tx, err := postgres.BeginTenantTx(ctx, r.conn, params.TenantId, true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	where, args := buildFilter(params)
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+		DECLARE orders_export NO SCROLL CURSOR FOR
+		SELECT id, latitude, longitude, subtotal, composite_tax_rate,
+		       tax_amount, total_amount, breakdown, jurisdictions, timestamp
+		FROM orders
+		%s
+		ORDER BY timestamp DESC
+	`, where), args...)
+	if err != nil {
+		return err
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM orders_export", streamBatchSize)
+	for {
+		rows, err := tx.QueryContext(ctx, fetch)
 		if err != nil {
 			return err
 		}
+
+		fetched := 0
+		for rows.Next() {
+			order, err := scanOrder(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			fetched++
+
+			if err := fn(order); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if fetched < streamBatchSize {
+			break
+		}
 	}
 
 	return tx.Commit()
 }
+
+func buildFilter(params entity.ListParams) (string, []interface{}) {
+	conditions := []string{"tenant_id = $1"}
+	args := []interface{}{params.TenantId}
+	i := 2
+
+	if params.State != "" {
+		conditions = append(conditions, fmt.Sprintf("jurisdictions->>'state' = $%d", i))
+		args = append(args, params.State)
+		i++
+	}
+	if params.County != "" {
+		conditions = append(conditions, fmt.Sprintf("jurisdictions->>'county' = $%d", i))
+		args = append(args, params.County)
+		i++
+	}
+	if params.City != "" {
+		conditions = append(conditions, fmt.Sprintf("jurisdictions->>'city' = $%d", i))
+		args = append(args, params.City)
+		i++
+	}
+	if params.From != nil {
+		conditions = append(conditions, fmt.Sprintf("timestamp >= $%d", i))
+		args = append(args, *params.From)
+		i++
+	}
+	if params.To != nil {
+		conditions = append(conditions, fmt.Sprintf("timestamp <= $%d", i))
+		args = append(args, *params.To)
+		i++
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func scanOrder(rows *sql.Rows) (*entity.Order, error) {
+	var order entity.Order
+	var breakdownData []byte
+	var jurisdictionsData []byte
+
+	err := rows.Scan(&order.Id, &order.Latitude, &order.Longitude, &order.Subtotal,
+		&order.CompositeTaxRate, &order.TaxAmount, &order.TotalAmount,
+		&breakdownData, &jurisdictionsData, &order.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(breakdownData, &order.Breakdown); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(jurisdictionsData, &order.Jurisdiction); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// Timeseries aggregates orders into buckets truncated in params.Location, so
+// a "day" bucket starts at local midnight rather than at UTC midnight.
+func (r *Repository) Timeseries(ctx context.Context,
+	params entity.TimeseriesParams) ([]*entity.TimeseriesPoint, error) {
+	groupColumn := "''"
+	groupBy := "1"
+	if params.GroupByCounty {
+		groupColumn = "COALESCE(jurisdictions->>'county', '')"
+		groupBy = "1, 2"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT date_trunc($1, timestamp, $2) AS bucket,
+		       %s AS county,
+		       COUNT(*), COALESCE(SUM(subtotal), 0),
+		       COALESCE(SUM(tax_amount), 0), COALESCE(SUM(total_amount), 0)
+		FROM orders
+		WHERE tenant_id = $5 AND timestamp >= $3 AND timestamp < $4
+		GROUP BY %s
+		ORDER BY %s
+	`, groupColumn, groupBy, groupBy)
+
+	tx, err := postgres.BeginTenantTx(ctx, r.conn, params.TenantId, true)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, params.Interval,
+		params.Location.String(), params.From, params.To, params.TenantId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := make([]*entity.TimeseriesPoint, 0)
+	for rows.Next() {
+		var point entity.TimeseriesPoint
+		if err := rows.Scan(&point.Bucket, &point.County, &point.Orders,
+			&point.Subtotal, &point.Tax, &point.Total); err != nil {
+			return nil, err
+		}
+		point.Bucket = point.Bucket.In(params.Location)
+		points = append(points, &point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+// Heatmap bins matching orders into the cells of grid and returns one
+// aggregate per non-empty cell, identified by its row and column index.
+func (r *Repository) Heatmap(ctx context.Context, filter entity.ListParams,
+	grid entity.Grid) ([]*entity.HeatmapCell, error) {
+	where, args := buildFilter(filter)
+	i := len(args) + 1
+
+	query := fmt.Sprintf(`
+		SELECT floor((latitude - $%d) / $%d)::bigint AS row,
+		       floor((longitude - $%d) / $%d)::bigint AS col,
+		       COUNT(*), COALESCE(SUM(subtotal), 0),
+		       COALESCE(SUM(tax_amount), 0), COALESCE(SUM(total_amount), 0)
+		FROM orders
+		%s
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`, i, i+1, i+2, i+3, where)
+
+	args = append(args, grid.OriginLatitude, grid.LatitudeStep,
+		grid.OriginLongitude, grid.LongitudeStep)
+
+	tx, err := postgres.BeginTenantTx(ctx, r.conn, filter.TenantId, true)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cells := make([]*entity.HeatmapCell, 0)
+	for rows.Next() {
+		var cell entity.HeatmapCell
+		if err := rows.Scan(&cell.Row, &cell.Column, &cell.Orders,
+			&cell.Subtotal, &cell.Tax, &cell.Total); err != nil {
+			return nil, err
+		}
+		cells = append(cells, &cell)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cells, nil
+}
+
+func importRow(order *entity.Order) *int {
+	if order.ImportJobId == nil {
+		return nil
+	}
+	return &order.ImportRow
+}
@@ -0,0 +1,17 @@
+package order
+
+import (
+	"InstantWellnessKits/src/repository/repotest"
+	"InstantWellnessKits/src/usecase"
+	"testing"
+)
+
+func TestOrders(t *testing.T) {
+	conn := repotest.OpenPostgres(t)
+	r := NewRepository(conn)
+	repotest.RunOrders(t, func(t *testing.T) (usecase.Orders, string) {
+		tenantId := repotest.NewTenantId(t, conn)
+		repotest.AddTenant(t, conn, tenantId)
+		return r, tenantId
+	})
+}
@@ -2,8 +2,12 @@ package tax_rate
 
 import (
 	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/metrics"
+	"InstantWellnessKits/src/repository/postgres"
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 
 	"github.com/shopspring/decimal"
 )
@@ -16,35 +20,159 @@ func NewRepository(conn *sql.DB) *Repository {
 	return &Repository{conn: conn}
 }
 
-func (r *Repository) Get(ctx context.Context, jurisdiction *entity.Jurisdiction) (decimal.Decimal,
+// Get resolves the rate for jurisdiction as seen by tenantId: exempt
+// tenants pay no tax, and a tenant override of a jurisdiction wins over the
+// published rate.
+func (r *Repository) Get(ctx context.Context, tenantId string, jurisdiction *entity.Jurisdiction) (decimal.Decimal,
 	*entity.TaxBreakdown, error) {
-	compositeRate, taxBreakdown, err := r.findRate(ctx, jurisdiction.City)
+	tx, err := postgres.BeginTenantTx(ctx, r.conn, tenantId, true)
+	if err != nil {
+		return decimal.Zero, nil, err
+	}
+	defer tx.Rollback()
+
+	var exempt bool
+	err = tx.QueryRowContext(ctx, "SELECT tax_exempt FROM tenants WHERE id = $1", tenantId).Scan(&exempt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return decimal.Zero, nil, err
+	}
+	if exempt {
+		metrics.TaxRateResolutions.WithLabelValues("exempt").Inc()
+		return decimal.Zero, entity.NewTaxBreakdown(decimal.Zero, decimal.Zero,
+			decimal.Zero, decimal.Zero), nil
+	}
+
+	compositeRate, taxBreakdown, err := r.findRate(ctx, tx, jurisdiction.City)
 	if err == nil {
+		metrics.TaxRateResolutions.WithLabelValues("city").Inc()
 		return compositeRate, taxBreakdown, nil
 	}
 
-	compositeRate, taxBreakdown, err = r.findRate(ctx, jurisdiction.County)
+	compositeRate, taxBreakdown, err = r.findRate(ctx, tx, jurisdiction.County)
 	if err != nil {
-		return r.findRate(ctx, "New York State")
+		compositeRate, taxBreakdown, err = r.findRate(ctx, tx, "New York State")
+		if err == nil {
+			metrics.TaxRateResolutions.WithLabelValues("state").Inc()
+		}
+		return compositeRate, taxBreakdown, err
 	}
 
+	metrics.TaxRateResolutions.WithLabelValues("county").Inc()
 	return compositeRate, taxBreakdown, nil
 }
 
-func (r *Repository) findRate(ctx context.Context, jurisdictionName string) (decimal.Decimal,
+func (r *Repository) findRate(ctx context.Context, tx *sql.Tx, jurisdictionName string) (decimal.Decimal,
 	*entity.TaxBreakdown, error) {
+	// Row-level security limits tenant_rate_overrides to the current tenant.
 	query := `
 		SELECT composite_rate, state_rate, county_rate, city_rate, special_rate
-		FROM tax_rates
-		WHERE jurisdiction_name = $1
+		FROM (
+			SELECT 0 AS priority, composite_rate, state_rate, county_rate, city_rate, special_rate
+			FROM tenant_rate_overrides
+			WHERE jurisdiction_name = $1
+			UNION ALL
+			SELECT 1, composite_rate, state_rate, county_rate, city_rate, special_rate
+			FROM tax_rates
+			WHERE jurisdiction_name = $1
+		) rates
+		ORDER BY priority
+		LIMIT 1
 	`
 	var compositeRate string
 	var taxBreakdown entity.TaxBreakdown
-	err := r.conn.QueryRowContext(ctx, query, jurisdictionName).
+	err := tx.QueryRowContext(ctx, query, jurisdictionName).
 		Scan(&compositeRate, &taxBreakdown.StateRate, &taxBreakdown.CountyRate,
 			&taxBreakdown.CityRate, &taxBreakdown.SpecialRate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return decimal.Zero, nil, fmt.Errorf("tax rate for %q: %w", jurisdictionName, entity.ErrNotFound)
+	}
 	if err != nil {
 		return decimal.Zero, nil, err
 	}
 	return decimal.RequireFromString(compositeRate), &taxBreakdown, nil
 }
+
+func (r *Repository) GetSettings(ctx context.Context, tenantId string) (*entity.TaxSettings, error) {
+	tx, err := postgres.BeginTenantTx(ctx, r.conn, tenantId, true)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	settings := &entity.TaxSettings{Overrides: make([]entity.RateOverride, 0)}
+	err = tx.QueryRowContext(ctx, "SELECT tax_exempt FROM tenants WHERE id = $1", tenantId).
+		Scan(&settings.Exempt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT jurisdiction_name, state_rate, county_rate, city_rate, special_rate
+		FROM tenant_rate_overrides
+		ORDER BY jurisdiction_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o entity.RateOverride
+		if err := rows.Scan(&o.JurisdictionName, &o.StateRate, &o.CountyRate,
+			&o.CityRate, &o.SpecialRate); err != nil {
+			return nil, err
+		}
+		settings.Overrides = append(settings.Overrides, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// SaveSettings replaces the tenant's exemption flag and its full set of
+// rate overrides.
+func (r *Repository) SaveSettings(ctx context.Context, tenantId string, settings *entity.TaxSettings) error {
+	tx, err := postgres.BeginTenantTx(ctx, r.conn, tenantId, false)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE tenants SET tax_exempt = $1 WHERE id = $2",
+		settings.Exempt, tenantId)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return entity.ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tenant_rate_overrides WHERE tenant_id = $1", tenantId); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO tenant_rate_overrides (
+			tenant_id, jurisdiction_name, composite_rate,
+			state_rate, county_rate, city_rate, special_rate
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	for _, o := range settings.Overrides {
+		_, err := tx.ExecContext(ctx, query, tenantId, o.JurisdictionName, o.CompositeRate(),
+			o.StateRate, o.CountyRate, o.CityRate, o.SpecialRate)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,16 @@
+package tax_rate
+
+import (
+	"InstantWellnessKits/src/repository/repotest"
+	"testing"
+)
+
+func TestTaxRates(t *testing.T) {
+	conn := repotest.OpenPostgres(t)
+	r := NewRepository(conn)
+	repotest.RunTaxRates(t, func(t *testing.T) (repotest.TaxRates, string) {
+		tenantId := repotest.NewTenantId(t, conn)
+		repotest.AddTenant(t, conn, tenantId)
+		return r, tenantId
+	})
+}
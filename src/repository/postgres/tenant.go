@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+)
+
+// BeginTenantTx opens a transaction that is subject to the row-level
+// security policies of tenantId: it drops to the iwk_tenant role and sets
+// app.tenant_id for the lifetime of the transaction only.
+func BeginTenantTx(ctx context.Context, conn *sql.DB, tenantId string, readOnly bool) (*sql.Tx, error) {
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: readOnly})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "SET LOCAL ROLE iwk_tenant"); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "SELECT set_config('app.tenant_id', $1, true)", tenantId); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,101 @@
+package repotest
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OpenAPIKeys returns a repository for one test and a tenant id that is
+// not registered yet, so that Create has to register it.
+type OpenAPIKeys func(t *testing.T) (usecase.APIKeys, string)
+
+// RunAPIKeys checks the behaviour every usecase.APIKeys backend shares.
+func RunAPIKeys(t *testing.T, open OpenAPIKeys) {
+	ctx := context.Background()
+
+	issue := func(t *testing.T, keys usecase.APIKeys, tenantId string, roles ...entity.Role) (*entity.APIKey, string) {
+		t.Helper()
+		key := entity.NewAPIKey("test", "iwk_"+uuid.NewString()[:8], tenantId, roles)
+		key.CreatedAt = key.CreatedAt.Truncate(time.Millisecond)
+		hash := usecase.HashAPIKey(uuid.NewString())
+		if err := keys.Create(ctx, key, hash); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		return key, hash
+	}
+
+	find := func(t *testing.T, keys usecase.APIKeys, id uuid.UUID) *entity.APIKey {
+		t.Helper()
+		listed, err := keys.List(ctx)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		i := slices.IndexFunc(listed, func(key *entity.APIKey) bool { return key.Id == id })
+		if i < 0 {
+			t.Fatalf("key %s is not listed", id)
+		}
+		return listed[i]
+	}
+
+	t.Run("FindActiveByHash", func(t *testing.T) {
+		keys, tenant := open(t)
+		key, hash := issue(t, keys, tenant, entity.RoleAnalyst, entity.RoleImporter)
+		issue(t, keys, tenant, entity.RoleRateAdmin)
+
+		found, err := keys.FindActiveByHash(ctx, hash)
+		if err != nil {
+			t.Fatalf("FindActiveByHash: %v", err)
+		}
+		if found.Id != key.Id || found.Name != key.Name || found.Prefix != key.Prefix ||
+			found.TenantId != tenant || !slices.Equal(found.Roles, key.Roles) ||
+			!found.CreatedAt.Equal(key.CreatedAt) || found.RevokedAt != nil {
+			t.Errorf("found %+v, want %+v", found, key)
+		}
+
+		if _, err := keys.FindActiveByHash(ctx, usecase.HashAPIKey("unknown")); !errors.Is(err, entity.ErrNotFound) {
+			t.Errorf("FindActiveByHash of an unknown key = %v, want %v", err, entity.ErrNotFound)
+		}
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		keys, tenant := open(t)
+		key, hash := issue(t, keys, tenant, entity.RoleOrderWriter)
+
+		if err := keys.Revoke(ctx, key.Id); err != nil {
+			t.Fatalf("Revoke: %v", err)
+		}
+		if _, err := keys.FindActiveByHash(ctx, hash); !errors.Is(err, entity.ErrNotFound) {
+			t.Errorf("FindActiveByHash of a revoked key = %v, want %v", err, entity.ErrNotFound)
+		}
+		if listed := find(t, keys, key.Id); listed.RevokedAt == nil {
+			t.Error("revoked key is listed without RevokedAt")
+		}
+
+		if err := keys.Revoke(ctx, key.Id); !errors.Is(err, entity.ErrNotFound) {
+			t.Errorf("second Revoke = %v, want %v", err, entity.ErrNotFound)
+		}
+		if err := keys.Revoke(ctx, uuid.New()); !errors.Is(err, entity.ErrNotFound) {
+			t.Errorf("Revoke of an unknown key = %v, want %v", err, entity.ErrNotFound)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		keys, tenant := open(t)
+		first, _ := issue(t, keys, tenant, entity.RoleAnalyst)
+		second, _ := issue(t, keys, tenant)
+
+		if listed := find(t, keys, first.Id); !slices.Equal(listed.Roles, first.Roles) {
+			t.Errorf("listed roles = %v, want %v", listed.Roles, first.Roles)
+		}
+		if listed := find(t, keys, second.Id); len(listed.Roles) != 0 {
+			t.Errorf("listed roles = %v, want none", listed.Roles)
+		}
+	})
+}
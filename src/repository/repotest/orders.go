@@ -0,0 +1,398 @@
+package repotest
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// OpenOrders returns a repository for one test and a tenant that holds
+// no orders in it. Backends that share a database between tests register
+// a fresh tenant each time.
+type OpenOrders func(t *testing.T) (usecase.Orders, string)
+
+// base is a Monday noon in UTC, 8am in New York.
+var base = time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+// newOrder is an order at the given place and time; the tax is 10% of
+// subtotal.
+func newOrder(tenantId string, at time.Time, county, city string, latitude, longitude float64,
+	subtotal string) *entity.Order {
+	amount := decimal.RequireFromString(subtotal)
+	rate := decimal.RequireFromString("0.1")
+	tax := amount.Mul(rate).Round(2)
+	order := entity.NewOrder(latitude, longitude, amount, rate, tax, amount.Add(tax),
+		entity.NewTaxBreakdown(decimal.RequireFromString("0.04"), decimal.RequireFromString("0.06"),
+			decimal.Zero, decimal.Zero),
+		entity.NewJurisdiction("New York", county, city, ""), at)
+	order.TenantId = tenantId
+	return order
+}
+
+// fixture is five orders of tenantId over two days and two counties,
+// listed newest first.
+func fixture(tenantId string) []*entity.Order {
+	return []*entity.Order{
+		newOrder(tenantId, base.Add(26*time.Hour), "Kings", "New York City", 40.65, -73.95, "30.00"),
+		newOrder(tenantId, base.Add(25*time.Hour), "Albany", "Albany", 42.65, -73.75, "20.00"),
+		newOrder(tenantId, base.Add(2*time.Hour), "Kings", "New York City", 40.69, -73.99, "15.50"),
+		newOrder(tenantId, base.Add(time.Hour), "Albany", "Albany", 42.61, -73.71, "10.00"),
+		newOrder(tenantId, base, "Kings", "New York City", 40.61, -73.91, "5.20"),
+	}
+}
+
+func create(t *testing.T, orders usecase.Orders, batch []*entity.Order) {
+	t.Helper()
+	if err := orders.CreateBatch(context.Background(), batch); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+}
+
+func list(t *testing.T, orders usecase.Orders, params entity.ListParams) *entity.ListResult {
+	t.Helper()
+	result, err := orders.List(context.Background(), params)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	return result
+}
+
+func ids(orders []*entity.Order) []uuid.UUID {
+	result := make([]uuid.UUID, len(orders))
+	for i, order := range orders {
+		result[i] = order.Id
+	}
+	return result
+}
+
+func assertIds(t *testing.T, got, want []*entity.Order) {
+	t.Helper()
+	gotIds, wantIds := ids(got), ids(want)
+	if len(gotIds) != len(wantIds) {
+		t.Fatalf("got %d orders %v, want %d %v", len(gotIds), gotIds, len(wantIds), wantIds)
+	}
+	for i := range gotIds {
+		if gotIds[i] != wantIds[i] {
+			t.Fatalf("order %d = %v, want %v", i, gotIds[i], wantIds[i])
+		}
+	}
+}
+
+func assertDecimal(t *testing.T, name string, got decimal.Decimal, want string) {
+	t.Helper()
+	if !got.Equal(decimal.RequireFromString(want)) {
+		t.Errorf("%s = %s, want %s", name, got, want)
+	}
+}
+
+// RunOrders checks the behaviour every usecase.Orders backend shares.
+func RunOrders(t *testing.T, open OpenOrders) {
+	ctx := context.Background()
+
+	t.Run("List", func(t *testing.T) {
+		orders, tenant := open(t)
+		all := fixture(tenant)
+		create(t, orders, all)
+
+		result := list(t, orders, entity.ListParams{TenantId: tenant, Last24hFrom: base.Add(24 * time.Hour)})
+		assertIds(t, result.Orders, all)
+		if result.Total != 5 || result.GlobalOrders != 5 || result.Last24hOrders != 2 {
+			t.Errorf("total, global, last 24h = %d, %d, %d, want 5, 5, 2",
+				result.Total, result.GlobalOrders, result.Last24hOrders)
+		}
+		assertDecimal(t, "global tax", result.GlobalTax, "8.07")
+		assertDecimal(t, "global total", result.GlobalGrand, "88.77")
+		assertDecimal(t, "last 24h tax", result.Last24hTax, "5.00")
+		assertDecimal(t, "last 24h total", result.Last24hGrand, "55.00")
+
+		got := result.Orders[2]
+		if got.Latitude != 40.69 || got.Longitude != -73.99 || !got.Timestamp.Equal(all[2].Timestamp) ||
+			got.Jurisdiction != all[2].Jurisdiction || got.Breakdown.CountyRate.String() != "0.06" {
+			t.Errorf("stored order = %+v, want %+v", got, all[2])
+		}
+		assertDecimal(t, "subtotal", got.Subtotal, "15.50")
+		assertDecimal(t, "rate", got.CompositeTaxRate, "0.1")
+		assertDecimal(t, "tax", got.TaxAmount, "1.55")
+		assertDecimal(t, "total", got.TotalAmount, "17.05")
+	})
+
+	t.Run("ListPages", func(t *testing.T) {
+		orders, tenant := open(t)
+		all := fixture(tenant)
+		create(t, orders, all)
+
+		for page, want := range [][]*entity.Order{all[:2], all[2:4], all[4:], {}} {
+			result := list(t, orders, entity.ListParams{TenantId: tenant, Page: page + 1, Limit: 2})
+			assertIds(t, result.Orders, want)
+			if result.Total != 5 {
+				t.Errorf("page %d total = %d, want 5", page+1, result.Total)
+			}
+		}
+	})
+
+	t.Run("ListFilters", func(t *testing.T) {
+		orders, tenant := open(t)
+		all := fixture(tenant)
+		create(t, orders, all)
+
+		from, to := base.Add(time.Hour), base.Add(25*time.Hour)
+		tests := []struct {
+			name   string
+			params entity.ListParams
+			want   []*entity.Order
+		}{
+			{"state", entity.ListParams{State: "New York"}, all},
+			{"other state", entity.ListParams{State: "New Jersey"}, nil},
+			{"county", entity.ListParams{County: "Albany"}, []*entity.Order{all[1], all[3]}},
+			{"city", entity.ListParams{City: "New York City"}, []*entity.Order{all[0], all[2], all[4]}},
+			{"inclusive range", entity.ListParams{From: &from, To: &to}, all[1:4]},
+			{"county and range", entity.ListParams{County: "Kings", From: &from, To: &to},
+				[]*entity.Order{all[2]}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.params.TenantId = tenant
+				result := list(t, orders, tt.params)
+				assertIds(t, result.Orders, tt.want)
+				if result.Total != len(tt.want) || result.GlobalOrders != 5 {
+					t.Errorf("total, global = %d, %d, want %d, 5", result.Total, result.GlobalOrders, len(tt.want))
+				}
+			})
+		}
+	})
+
+	t.Run("TenantIsolation", func(t *testing.T) {
+		orders, tenant := open(t)
+		create(t, orders, fixture(tenant))
+
+		result := list(t, orders, entity.ListParams{TenantId: tenant + "-other"})
+		if len(result.Orders) != 0 || result.Total != 0 || result.GlobalOrders != 0 {
+			t.Errorf("other tenant sees %d orders, total %d, global %d",
+				len(result.Orders), result.Total, result.GlobalOrders)
+		}
+	})
+
+	t.Run("CreateBatchMixedTenants", func(t *testing.T) {
+		orders, tenant := open(t)
+		batch := fixture(tenant)
+		batch[3].TenantId = tenant + "-other"
+
+		if err := orders.CreateBatch(ctx, batch); !errors.Is(err, entity.ErrMixedTenants) {
+			t.Fatalf("CreateBatch = %v, want %v", err, entity.ErrMixedTenants)
+		}
+		if result := list(t, orders, entity.ListParams{TenantId: tenant}); result.Total != 0 {
+			t.Errorf("stored %d orders of a refused batch", result.Total)
+		}
+	})
+
+	t.Run("CreateBatchDuplicateId", func(t *testing.T) {
+		orders, tenant := open(t)
+		all := fixture(tenant)
+		create(t, orders, all[:1])
+
+		batch := all[1:]
+		batch[2].Id = all[0].Id
+		if err := orders.CreateBatch(ctx, batch); !errors.Is(err, entity.ErrRejected) {
+			t.Fatalf("CreateBatch = %v, want %v", err, entity.ErrRejected)
+		}
+		if result := list(t, orders, entity.ListParams{TenantId: tenant}); result.Total != 1 {
+			t.Errorf("stored %d orders, want only the first batch", result.Total)
+		}
+	})
+
+	t.Run("CreateBatchSkipsImportedRows", func(t *testing.T) {
+		orders, tenant := open(t)
+		jobId := uuid.New()
+		all := fixture(tenant)
+		for i, order := range all {
+			order.ImportJobId, order.ImportRow = &jobId, i
+		}
+		create(t, orders, all[:3])
+
+		// A retried chunk repeats rows that are already in, with the
+		// same ids, followed by new ones.
+		create(t, orders, all[1:])
+		result := list(t, orders, entity.ListParams{TenantId: tenant})
+		assertIds(t, result.Orders, all)
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		orders, tenant := open(t)
+		order := fixture(tenant)[0]
+
+		if _, err := orders.Create(ctx, order); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		assertIds(t, list(t, orders, entity.ListParams{TenantId: tenant}).Orders, []*entity.Order{order})
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		orders, tenant := open(t)
+		all := fixture(tenant)
+		create(t, orders, all)
+
+		var streamed []*entity.Order
+		err := orders.Stream(ctx, entity.ListParams{TenantId: tenant, County: "Kings"},
+			func(order *entity.Order) error {
+				streamed = append(streamed, order)
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("Stream: %v", err)
+		}
+		assertIds(t, streamed, []*entity.Order{all[0], all[2], all[4]})
+
+		stop := errors.New("stop")
+		calls := 0
+		err = orders.Stream(ctx, entity.ListParams{TenantId: tenant}, func(*entity.Order) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("Stream after fn failed = %v with %d calls, want %v with 1", err, calls, stop)
+		}
+	})
+
+	t.Run("UpdateTaxes", func(t *testing.T) {
+		orders, tenant := open(t)
+		all := fixture(tenant)
+		create(t, orders, all)
+
+		updated := *all[2]
+		updated.CompositeTaxRate = decimal.RequireFromString("0.08875")
+		updated.TaxAmount = decimal.RequireFromString("1.38")
+		updated.TotalAmount = decimal.RequireFromString("16.88")
+		updated.Breakdown = *entity.NewTaxBreakdown(decimal.RequireFromString("0.04"), decimal.Zero,
+			decimal.RequireFromString("0.045"), decimal.RequireFromString("0.00375"))
+		if err := orders.UpdateTaxes(ctx, tenant, []*entity.Order{&updated}); err != nil {
+			t.Fatalf("UpdateTaxes: %v", err)
+		}
+
+		// Another tenant cannot update the order, even knowing its id.
+		foreign := updated
+		foreign.TaxAmount = decimal.RequireFromString("99.99")
+		if err := orders.UpdateTaxes(ctx, tenant+"-other", []*entity.Order{&foreign}); err != nil {
+			t.Fatalf("UpdateTaxes of another tenant: %v", err)
+		}
+
+		got := list(t, orders, entity.ListParams{TenantId: tenant}).Orders[2]
+		assertDecimal(t, "rate", got.CompositeTaxRate, "0.08875")
+		assertDecimal(t, "tax", got.TaxAmount, "1.38")
+		assertDecimal(t, "total", got.TotalAmount, "16.88")
+		assertDecimal(t, "subtotal", got.Subtotal, "15.50")
+		assertDecimal(t, "special rate", got.Breakdown.SpecialRate, "0.00375")
+	})
+
+	t.Run("DeleteByImport", func(t *testing.T) {
+		orders, tenant := open(t)
+		jobId := uuid.New()
+		all := fixture(tenant)
+		for i, order := range all[:3] {
+			order.ImportJobId, order.ImportRow = &jobId, i
+		}
+		create(t, orders, all)
+
+		deleted, err := orders.DeleteByImport(ctx, tenant+"-other", jobId)
+		if err != nil || deleted != 0 {
+			t.Fatalf("DeleteByImport of another tenant = %d, %v, want 0", deleted, err)
+		}
+		deleted, err = orders.DeleteByImport(ctx, tenant, jobId)
+		if err != nil || deleted != 3 {
+			t.Fatalf("DeleteByImport = %d, %v, want 3", deleted, err)
+		}
+		assertIds(t, list(t, orders, entity.ListParams{TenantId: tenant}).Orders, all[3:])
+
+		// The rows are free again, so running the job once more
+		// inserts them.
+		create(t, orders, all[:3])
+		assertIds(t, list(t, orders, entity.ListParams{TenantId: tenant}).Orders, all)
+	})
+
+	t.Run("Timeseries", func(t *testing.T) {
+		orders, tenant := open(t)
+		create(t, orders, fixture(tenant))
+		newYork, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Fatal(err)
+		}
+		day := func(d int) time.Time { return time.Date(2025, 3, 10+d, 0, 0, 0, 0, newYork) }
+
+		params := entity.TimeseriesParams{TenantId: tenant, Interval: entity.IntervalDay,
+			From: base, To: base.Add(26 * time.Hour), Location: newYork}
+		points, err := orders.Timeseries(ctx, params)
+		if err != nil {
+			t.Fatalf("Timeseries: %v", err)
+		}
+		// To is exclusive, so the newest order is left out.
+		want := []entity.TimeseriesPoint{
+			{Bucket: day(0), Orders: 3, Subtotal: decimal.RequireFromString("30.70")},
+			{Bucket: day(1), Orders: 1, Subtotal: decimal.RequireFromString("20.00")},
+		}
+		assertPoints(t, points, want)
+
+		params.GroupByCounty, params.To = true, base.Add(48*time.Hour)
+		if points, err = orders.Timeseries(ctx, params); err != nil {
+			t.Fatalf("Timeseries by county: %v", err)
+		}
+		want = []entity.TimeseriesPoint{
+			{Bucket: day(0), County: "Albany", Orders: 1, Subtotal: decimal.RequireFromString("10.00")},
+			{Bucket: day(0), County: "Kings", Orders: 2, Subtotal: decimal.RequireFromString("20.70")},
+			{Bucket: day(1), County: "Albany", Orders: 1, Subtotal: decimal.RequireFromString("20.00")},
+			{Bucket: day(1), County: "Kings", Orders: 1, Subtotal: decimal.RequireFromString("30.00")},
+		}
+		assertPoints(t, points, want)
+		assertDecimal(t, "tax", points[1].Tax, "2.07")
+		assertDecimal(t, "total", points[1].Total, "22.77")
+	})
+
+	t.Run("Heatmap", func(t *testing.T) {
+		orders, tenant := open(t)
+		create(t, orders, fixture(tenant))
+
+		grid := entity.Grid{OriginLatitude: 40.5, OriginLongitude: -74, LatitudeStep: 0.5, LongitudeStep: 0.5}
+		cells, err := orders.Heatmap(ctx, entity.ListParams{TenantId: tenant}, grid)
+		if err != nil {
+			t.Fatalf("Heatmap: %v", err)
+		}
+		want := []entity.HeatmapCell{
+			{Row: 0, Column: 0, Orders: 3, Subtotal: decimal.RequireFromString("50.70")},
+			{Row: 4, Column: 0, Orders: 2, Subtotal: decimal.RequireFromString("30.00")},
+		}
+		if len(cells) != len(want) {
+			t.Fatalf("got %d cells, want %d", len(cells), len(want))
+		}
+		for i, cell := range cells {
+			if cell.Row != want[i].Row || cell.Column != want[i].Column || cell.Orders != want[i].Orders ||
+				!cell.Subtotal.Equal(want[i].Subtotal) {
+				t.Errorf("cell %d = %+v, want %+v", i, cell, want[i])
+			}
+		}
+
+		cells, err = orders.Heatmap(ctx, entity.ListParams{TenantId: tenant, County: "Albany"}, grid)
+		if err != nil {
+			t.Fatalf("Heatmap of a county: %v", err)
+		}
+		if len(cells) != 1 || cells[0].Row != 4 || cells[0].Orders != 2 {
+			t.Errorf("Albany cells = %+v, want one in row 4 with 2 orders", cells)
+		}
+	})
+}
+
+func assertPoints(t *testing.T, got []*entity.TimeseriesPoint, want []entity.TimeseriesPoint) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d points, want %d", len(got), len(want))
+	}
+	for i, point := range got {
+		if !point.Bucket.Equal(want[i].Bucket) || point.County != want[i].County ||
+			point.Orders != want[i].Orders || !point.Subtotal.Equal(want[i].Subtotal) {
+			t.Errorf("point %d = %+v, want %+v", i, point, want[i])
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package repotest
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/usecase"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// TaxRates is what a backend provides for tax rates and tenant settings.
+type TaxRates interface {
+	usecase.TaxRates
+	usecase.TaxSettings
+}
+
+// OpenTaxRates returns a repository seeded with the published rates and a
+// tenant registered in it with no exemption or overrides.
+type OpenTaxRates func(t *testing.T) (TaxRates, string)
+
+// RunTaxRates checks the behaviour every tax rate backend shares.
+func RunTaxRates(t *testing.T, open OpenTaxRates) {
+	ctx := context.Background()
+	kings := entity.NewJurisdiction("New York", "Kings", "New York City", "")
+	albany := entity.NewJurisdiction("New York", "Albany", "Colonie", "")
+	nowhere := entity.NewJurisdiction("New York", "Nowhere", "Nowhere", "")
+
+	assertRate := func(t *testing.T, rates TaxRates, tenantId string, jurisdiction *entity.Jurisdiction,
+		want string) *entity.TaxBreakdown {
+		t.Helper()
+		rate, breakdown, err := rates.Get(ctx, tenantId, jurisdiction)
+		if err != nil {
+			t.Fatalf("Get %s: %v", jurisdiction.City, err)
+		}
+		assertDecimal(t, jurisdiction.City+" rate", rate, want)
+		return breakdown
+	}
+
+	t.Run("Published", func(t *testing.T) {
+		rates, tenant := open(t)
+
+		breakdown := assertRate(t, rates, tenant, kings, "0.08875")
+		assertDecimal(t, "city rate", breakdown.CityRate, "0.045")
+		assertDecimal(t, "special rate", breakdown.SpecialRate, "0.00375")
+		// Without a rate of its own, a city falls back to its county
+		// and then to the state.
+		assertRate(t, rates, tenant, albany, "0.08")
+		breakdown = assertRate(t, rates, tenant, nowhere, "0.04")
+		assertDecimal(t, "state rate", breakdown.StateRate, "0.04")
+	})
+
+	t.Run("DefaultSettings", func(t *testing.T) {
+		rates, tenant := open(t)
+
+		settings, err := rates.GetSettings(ctx, tenant)
+		if err != nil {
+			t.Fatalf("GetSettings: %v", err)
+		}
+		if settings.Exempt || settings.Overrides == nil || len(settings.Overrides) != 0 {
+			t.Errorf("settings = %+v, want not exempt and an empty list of overrides", settings)
+		}
+	})
+
+	t.Run("UnknownTenant", func(t *testing.T) {
+		rates, tenant := open(t)
+
+		if _, err := rates.GetSettings(ctx, tenant+"-unknown"); !errors.Is(err, entity.ErrNotFound) {
+			t.Errorf("GetSettings = %v, want %v", err, entity.ErrNotFound)
+		}
+		err := rates.SaveSettings(ctx, tenant+"-unknown", &entity.TaxSettings{Exempt: true})
+		if !errors.Is(err, entity.ErrNotFound) {
+			t.Errorf("SaveSettings = %v, want %v", err, entity.ErrNotFound)
+		}
+	})
+
+	t.Run("Overrides", func(t *testing.T) {
+		rates, tenant := open(t)
+
+		albanyOverride := entity.RateOverride{JurisdictionName: "Albany",
+			StateRate: decimal.RequireFromString("0.04"), CountyRate: decimal.RequireFromString("0.02"),
+			CityRate: decimal.Zero, SpecialRate: decimal.RequireFromString("0.001")}
+		nycOverride := entity.RateOverride{JurisdictionName: "New York City",
+			StateRate: decimal.RequireFromString("0.04"), CountyRate: decimal.Zero,
+			CityRate: decimal.RequireFromString("0.03"), SpecialRate: decimal.Zero}
+		err := rates.SaveSettings(ctx, tenant, &entity.TaxSettings{
+			Overrides: []entity.RateOverride{nycOverride, albanyOverride}})
+		if err != nil {
+			t.Fatalf("SaveSettings: %v", err)
+		}
+
+		breakdown := assertRate(t, rates, tenant, albany, "0.061")
+		assertDecimal(t, "county rate", breakdown.CountyRate, "0.02")
+		assertRate(t, rates, tenant, kings, "0.07")
+		// Other tenants still see the published rates.
+		assertRate(t, rates, tenant+"-other", albany, "0.08")
+
+		settings, err := rates.GetSettings(ctx, tenant)
+		if err != nil {
+			t.Fatalf("GetSettings: %v", err)
+		}
+		if len(settings.Overrides) != 2 || settings.Overrides[0].JurisdictionName != "Albany" ||
+			settings.Overrides[1].JurisdictionName != "New York City" {
+			t.Fatalf("overrides = %+v, want Albany and New York City by name", settings.Overrides)
+		}
+		assertDecimal(t, "saved special rate", settings.Overrides[0].SpecialRate, "0.001")
+
+		// Saving replaces the whole set.
+		err = rates.SaveSettings(ctx, tenant, &entity.TaxSettings{Overrides: []entity.RateOverride{nycOverride}})
+		if err != nil {
+			t.Fatalf("SaveSettings: %v", err)
+		}
+		assertRate(t, rates, tenant, albany, "0.08")
+		if settings, err = rates.GetSettings(ctx, tenant); err != nil || len(settings.Overrides) != 1 {
+			t.Errorf("GetSettings = %+v, %v, want only New York City", settings, err)
+		}
+	})
+
+	t.Run("Exempt", func(t *testing.T) {
+		rates, tenant := open(t)
+
+		if err := rates.SaveSettings(ctx, tenant, &entity.TaxSettings{Exempt: true}); err != nil {
+			t.Fatalf("SaveSettings: %v", err)
+		}
+		breakdown := assertRate(t, rates, tenant, kings, "0")
+		assertDecimal(t, "state rate", breakdown.StateRate, "0")
+		assertRate(t, rates, tenant+"-other", kings, "0.08875")
+
+		settings, err := rates.GetSettings(ctx, tenant)
+		if err != nil || !settings.Exempt {
+			t.Errorf("GetSettings = %+v, %v, want exempt", settings, err)
+		}
+	})
+}
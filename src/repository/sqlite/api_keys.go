@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const apiKeyColumns = `id, name, key_prefix, roles, tenant_id, created_at, revoked_at`
+
+type APIKeys struct {
+	conn *sql.DB
+}
+
+func NewAPIKeys(conn *sql.DB) *APIKeys {
+	return &APIKeys{conn: conn}
+}
+
+// Create stores key, registering its tenant first if it is new.
+func (r *APIKeys) Create(ctx context.Context, key *entity.APIKey, hash string) error {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "INSERT OR IGNORE INTO tenants (id, name) VALUES (?, ?)",
+		key.TenantId, key.TenantId)
+	if err != nil {
+		return err
+	}
+
+	roles := make([]string, 0, len(key.Roles))
+	for _, role := range key.Roles {
+		roles = append(roles, string(role))
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO api_keys (id, name, key_prefix, key_hash, roles, tenant_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, key.Id.String(), key.Name, key.Prefix, hash, strings.Join(roles, ","), key.TenantId,
+		key.CreatedAt.UnixNano())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindActiveByHash returns the non-revoked key with the given hash.
+func (r *APIKeys) FindActiveByHash(ctx context.Context, hash string) (*entity.APIKey, error) {
+	row := r.conn.QueryRowContext(ctx, "SELECT "+apiKeyColumns+
+		" FROM api_keys WHERE key_hash = ? AND revoked_at IS NULL", hash)
+	key, err := scanAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+	return key, err
+}
+
+func (r *APIKeys) List(ctx context.Context) ([]*entity.APIKey, error) {
+	rows, err := r.conn.QueryContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys ORDER BY created_at")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*entity.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeys) Revoke(ctx context.Context, id uuid.UUID) error {
+	res, err := r.conn.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL",
+		time.Now().UnixNano(), id.String())
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return entity.ErrNotFound
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row scanner) (*entity.APIKey, error) {
+	var key entity.APIKey
+	var id, roles string
+	var createdAt int64
+	var revokedAt sql.NullInt64
+	if err := row.Scan(&id, &key.Name, &key.Prefix, &roles, &key.TenantId, &createdAt, &revokedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if key.Id, err = uuid.Parse(id); err != nil {
+		return nil, err
+	}
+	key.CreatedAt = time.Unix(0, createdAt)
+	if revokedAt.Valid {
+		t := time.Unix(0, revokedAt.Int64)
+		key.RevokedAt = &t
+	}
+	for _, role := range strings.Split(roles, ",") {
+		if role != "" {
+			key.Roles = append(key.Roles, entity.Role(role))
+		}
+	}
+
+	return &key, nil
+}
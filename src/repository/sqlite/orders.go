@@ -0,0 +1,300 @@
+package sqlite
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/repository/internal/query"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const orderColumns = `id, latitude, longitude, subtotal_cents, composite_tax_rate, tax_cents,
+	total_cents, breakdown, state, county, city, special, timestamp`
+
+type Orders struct {
+	conn *sql.DB
+}
+
+func NewOrders(conn *sql.DB) *Orders {
+	return &Orders{conn: conn}
+}
+
+func (r *Orders) Create(ctx context.Context, order *entity.Order) (*entity.Order, error) {
+	if err := r.CreateBatch(ctx, []*entity.Order{order}); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// CreateBatch inserts orders of a single tenant in one transaction,
+// skipping rows an import job has already inserted.
+func (r *Orders) CreateBatch(ctx context.Context, orders []*entity.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	for _, order := range orders {
+		if order.TenantId != orders[0].TenantId {
+			return entity.ErrMixedTenants
+		}
+	}
+
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO orders (`+orderColumns+`, tenant_id, import_job_id, import_row)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (import_job_id, import_row) DO NOTHING
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, order := range orders {
+		breakdownJSON, err := json.Marshal(order.Breakdown)
+		if err != nil {
+			return err
+		}
+		var importJobId, importRow any
+		if order.ImportJobId != nil {
+			importJobId, importRow = order.ImportJobId.String(), order.ImportRow
+		}
+
+		_, err = stmt.ExecContext(ctx, order.Id.String(), order.Latitude, order.Longitude,
+			cents(order.Subtotal), order.CompositeTaxRate.Round(5).String(), cents(order.TaxAmount),
+			cents(order.TotalAmount), breakdownJSON, order.Jurisdiction.State, order.Jurisdiction.County,
+			order.Jurisdiction.City, order.Jurisdiction.Special, order.Timestamp.UnixNano(),
+			order.TenantId, importJobId, importRow)
+		if isConstraint(err) {
+			return fmt.Errorf("%w: %v", entity.ErrRejected, err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *Orders) List(ctx context.Context, params entity.ListParams) (*entity.ListResult, error) {
+	tx, err := r.conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	where, args := buildFilter(params)
+
+	result := &entity.ListResult{}
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM orders "+where, args...).
+		Scan(&result.Total); err != nil {
+		return nil, err
+	}
+
+	var globalTax, globalGrand, last24hTax, last24hGrand int64
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*), COALESCE(SUM(tax_cents), 0), COALESCE(SUM(total_cents), 0) FROM orders WHERE tenant_id = ?",
+		params.TenantId,
+	).Scan(&result.GlobalOrders, &globalTax, &globalGrand); err != nil {
+		return nil, err
+	}
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*), COALESCE(SUM(tax_cents), 0), COALESCE(SUM(total_cents), 0) FROM orders WHERE tenant_id = ? AND timestamp >= ?",
+		params.TenantId, params.Last24hFrom.UnixNano(),
+	).Scan(&result.Last24hOrders, &last24hTax, &last24hGrand); err != nil {
+		return nil, err
+	}
+	result.GlobalTax, result.GlobalGrand = fromCents(globalTax), fromCents(globalGrand)
+	result.Last24hTax, result.Last24hGrand = fromCents(last24hTax), fromCents(last24hGrand)
+
+	if params.Limit <= 0 {
+		params.Limit = 20
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	offset := (params.Page - 1) * params.Limit
+
+	rows, err := tx.QueryContext(ctx, "SELECT "+orderColumns+" FROM orders "+where+
+		" ORDER BY timestamp DESC, id LIMIT ? OFFSET ?", append(args, params.Limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+	result.Orders, err = scanOrders(rows, params.TenantId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Stream calls fn for every matching order, newest first. SQLite reads
+// the rows lazily; fn may write, as readers do not block the writer.
+func (r *Orders) Stream(ctx context.Context, params entity.ListParams, fn func(*entity.Order) error) error {
+	where, args := buildFilter(params)
+	rows, err := r.conn.QueryContext(ctx, "SELECT "+orderColumns+" FROM orders "+where+
+		" ORDER BY timestamp DESC, id", args...)
+	if err != nil {
+		return err
+	}
+	_, err = scanOrders(rows, params.TenantId, fn)
+	return err
+}
+
+func (r *Orders) UpdateTaxes(ctx context.Context, tenantId string, orders []*entity.Order) error {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		UPDATE orders
+		SET composite_tax_rate = ?, tax_cents = ?, total_cents = ?, breakdown = ?
+		WHERE id = ? AND tenant_id = ?
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, order := range orders {
+		breakdownJSON, err := json.Marshal(order.Breakdown)
+		if err != nil {
+			return err
+		}
+		_, err = stmt.ExecContext(ctx, order.CompositeTaxRate.Round(5).String(), cents(order.TaxAmount),
+			cents(order.TotalAmount), breakdownJSON, order.Id.String(), tenantId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *Orders) DeleteByImport(ctx context.Context, tenantId string, importJobId uuid.UUID) (int64, error) {
+	res, err := r.conn.ExecContext(ctx, "DELETE FROM orders WHERE tenant_id = ? AND import_job_id = ?",
+		tenantId, importJobId.String())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Timeseries buckets in Go: SQLite has no time zones to truncate in.
+func (r *Orders) Timeseries(ctx context.Context, params entity.TimeseriesParams) ([]*entity.TimeseriesPoint, error) {
+	rows, err := r.conn.QueryContext(ctx, "SELECT "+orderColumns+
+		" FROM orders WHERE tenant_id = ? AND timestamp >= ? AND timestamp < ?",
+		params.TenantId, params.From.UnixNano(), params.To.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	orders, err := scanOrders(rows, params.TenantId, nil)
+	if err != nil {
+		return nil, err
+	}
+	return query.Timeseries(orders, params), nil
+}
+
+func (r *Orders) Heatmap(ctx context.Context, filter entity.ListParams, grid entity.Grid) ([]*entity.HeatmapCell, error) {
+	where, args := buildFilter(filter)
+	rows, err := r.conn.QueryContext(ctx, "SELECT "+orderColumns+" FROM orders "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := scanOrders(rows, filter.TenantId, nil)
+	if err != nil {
+		return nil, err
+	}
+	return query.Heatmap(orders, grid), nil
+}
+
+func buildFilter(params entity.ListParams) (string, []any) {
+	conditions := []string{"tenant_id = ?"}
+	args := []any{params.TenantId}
+
+	if params.State != "" {
+		conditions = append(conditions, "state = ?")
+		args = append(args, params.State)
+	}
+	if params.County != "" {
+		conditions = append(conditions, "county = ?")
+		args = append(args, params.County)
+	}
+	if params.City != "" {
+		conditions = append(conditions, "city = ?")
+		args = append(args, params.City)
+	}
+	if params.From != nil {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, params.From.UnixNano())
+	}
+	if params.To != nil {
+		conditions = append(conditions, "timestamp <= ?")
+		args = append(args, params.To.UnixNano())
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// scanOrders reads and closes rows. With fn set it hands each order to fn
+// instead of collecting them.
+func scanOrders(rows *sql.Rows, tenantId string, fn func(*entity.Order) error) ([]*entity.Order, error) {
+	defer rows.Close()
+
+	orders := make([]*entity.Order, 0)
+	for rows.Next() {
+		var order entity.Order
+		var id, compositeRate string
+		var subtotal, tax, total, timestamp int64
+		var breakdownData []byte
+		err := rows.Scan(&id, &order.Latitude, &order.Longitude, &subtotal, &compositeRate, &tax,
+			&total, &breakdownData, &order.Jurisdiction.State, &order.Jurisdiction.County,
+			&order.Jurisdiction.City, &order.Jurisdiction.Special, &timestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		if order.Id, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		if order.CompositeTaxRate, err = decimal.NewFromString(compositeRate); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(breakdownData, &order.Breakdown); err != nil {
+			return nil, err
+		}
+		order.Subtotal, order.TaxAmount, order.TotalAmount = fromCents(subtotal), fromCents(tax), fromCents(total)
+		order.Timestamp = time.Unix(0, timestamp).UTC()
+		order.TenantId = tenantId
+
+		if fn == nil {
+			orders = append(orders, &order)
+			continue
+		}
+		if err := fn(&order); err != nil {
+			return nil, err
+		}
+	}
+
+	return orders, rows.Err()
+}
+
+func cents(amount decimal.Decimal) int64 {
+	return amount.Shift(2).Round(0).IntPart()
+}
+
+func fromCents(cents int64) decimal.Decimal {
+	return decimal.New(cents, -2)
+}
@@ -0,0 +1,67 @@
+-- Mirrors the Postgres schema for the tables this backend covers. Amounts
+-- are stored in cents and timestamps in Unix nanoseconds, so that sums and
+-- comparisons are exact; the jurisdiction is split out for filtering.
+CREATE TABLE IF NOT EXISTS tax_rates (
+    jurisdiction_type TEXT NOT NULL,
+    jurisdiction_name TEXT NOT NULL,
+    composite_rate TEXT NOT NULL,
+    state_rate TEXT NOT NULL,
+    county_rate TEXT NOT NULL,
+    city_rate TEXT NOT NULL,
+    special_rate TEXT NOT NULL,
+    special_name TEXT,
+    UNIQUE (jurisdiction_type, jurisdiction_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_rates_name ON tax_rates(jurisdiction_name);
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    tax_exempt INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT OR IGNORE INTO tenants (id, name) VALUES ('default', 'Default');
+
+CREATE TABLE IF NOT EXISTS tenant_rate_overrides (
+    tenant_id TEXT NOT NULL REFERENCES tenants(id),
+    jurisdiction_name TEXT NOT NULL,
+    state_rate TEXT NOT NULL,
+    county_rate TEXT NOT NULL,
+    city_rate TEXT NOT NULL,
+    special_rate TEXT NOT NULL,
+    PRIMARY KEY (tenant_id, jurisdiction_name)
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    latitude REAL NOT NULL,
+    longitude REAL NOT NULL,
+    subtotal_cents INTEGER NOT NULL,
+    composite_tax_rate TEXT NOT NULL,
+    tax_cents INTEGER NOT NULL,
+    total_cents INTEGER NOT NULL,
+    breakdown TEXT NOT NULL,
+    state TEXT NOT NULL,
+    county TEXT NOT NULL,
+    city TEXT NOT NULL,
+    special TEXT NOT NULL,
+    timestamp INTEGER NOT NULL,
+    import_job_id TEXT,
+    import_row INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_tenant_timestamp ON orders(tenant_id, timestamp DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_import_row ON orders(import_job_id, import_row);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    key_prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    roles TEXT NOT NULL,
+    tenant_id TEXT NOT NULL REFERENCES tenants(id),
+    created_at INTEGER NOT NULL,
+    revoked_at INTEGER
+);
@@ -0,0 +1,94 @@
+// Package sqlite keeps orders and tax rates in an embedded SQLite file,
+// for running the service on a laptop without Postgres. The driver is
+// pure Go, so it builds without cgo.
+package sqlite
+
+import (
+	"InstantWellnessKits/src/migrations"
+	"bytes"
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	_ "modernc.org/sqlite"
+)
+
+//go:embed schema.sql
+var schema string
+
+// InitDb opens the database file at path, creating it and its schema if
+// needed, and seeds the published tax rates.
+func InitDb(ctx context.Context, path string) (*sql.DB, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	// WAL lets readers run alongside the single writer, and concurrent
+	// writers wait for each other instead of failing.
+	conn, err := sql.Open("sqlite", "file:"+path+
+		"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(10000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, schema); err != nil {
+		return nil, errors.Join(fmt.Errorf("creating sqlite schema: %w", err), conn.Close())
+	}
+	if err := seedTaxRates(ctx, conn); err != nil {
+		return nil, errors.Join(err, conn.Close())
+	}
+
+	return conn, nil
+}
+
+func seedTaxRates(ctx context.Context, conn *sql.DB) error {
+	records, err := csv.NewReader(bytes.NewReader(migrations.TaxRates)).ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to read tax rates: %w", err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO tax_rates (
+			jurisdiction_type, jurisdiction_name, composite_rate,
+			state_rate, county_rate, city_rate, special_rate, special_name
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''))
+		ON CONFLICT (jurisdiction_type, jurisdiction_name) DO NOTHING
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var inserted int64
+	for _, row := range records[1:] {
+		res, err := stmt.ExecContext(ctx, row[0], row[1], row[2], row[3], row[4], row[5], row[6], row[7])
+		if err != nil {
+			return fmt.Errorf("failed to insert row %v: %w", row, err)
+		}
+		affected, _ := res.RowsAffected()
+		inserted += affected
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if inserted > 0 {
+		slog.InfoContext(ctx, "Seeded tax rates", "inserted", inserted)
+	}
+	return nil
+}
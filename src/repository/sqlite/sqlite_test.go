@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"InstantWellnessKits/src/repository/repotest"
+	"InstantWellnessKits/src/usecase"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+const tenant = "acme"
+
+func openDb(t *testing.T) *sql.DB {
+	t.Helper()
+	conn, err := InitDb(context.Background(), filepath.Join(t.TempDir(), "iwk.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestOrders(t *testing.T) {
+	repotest.RunOrders(t, func(t *testing.T) (usecase.Orders, string) {
+		return NewOrders(openDb(t)), tenant
+	})
+}
+
+func TestTaxRates(t *testing.T) {
+	repotest.RunTaxRates(t, func(t *testing.T) (repotest.TaxRates, string) {
+		rates := NewTaxRates(openDb(t))
+		if err := rates.AddTenant(context.Background(), tenant); err != nil {
+			t.Fatal(err)
+		}
+		return rates, tenant
+	})
+}
+
+func TestAPIKeys(t *testing.T) {
+	repotest.RunAPIKeys(t, func(t *testing.T) (usecase.APIKeys, string) {
+		return NewAPIKeys(openDb(t)), tenant
+	})
+}
+
+// A reopened file keeps what was written to it.
+func TestInitDbReopens(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "iwk.db")
+	conn, err := InitDb(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewTaxRates(conn).AddTenant(ctx, tenant); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err = InitDb(ctx, path)
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer conn.Close()
+	var rates int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM tax_rates").Scan(&rates); err != nil {
+		t.Fatal(err)
+	}
+	if rates != 85 {
+		t.Errorf("%d tax rates after reopening, want the 85 published once", rates)
+	}
+	if _, err := NewTaxRates(conn).GetSettings(ctx, tenant); err != nil {
+		t.Errorf("GetSettings after reopening: %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package sqlite
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/metrics"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+type TaxRates struct {
+	conn *sql.DB
+}
+
+func NewTaxRates(conn *sql.DB) *TaxRates {
+	return &TaxRates{conn: conn}
+}
+
+// AddTenant registers tenantId, if it is new, with no exemption or
+// overrides.
+func (r *TaxRates) AddTenant(ctx context.Context, tenantId string) error {
+	_, err := r.conn.ExecContext(ctx, "INSERT OR IGNORE INTO tenants (id, name) VALUES (?, ?)",
+		tenantId, tenantId)
+	return err
+}
+
+// Get resolves the rate for jurisdiction as seen by tenantId: exempt
+// tenants pay no tax, and a tenant override of a jurisdiction wins over the
+// published rate.
+func (r *TaxRates) Get(ctx context.Context, tenantId string, jurisdiction *entity.Jurisdiction) (decimal.Decimal,
+	*entity.TaxBreakdown, error) {
+	var exempt bool
+	err := r.conn.QueryRowContext(ctx, "SELECT tax_exempt FROM tenants WHERE id = ?", tenantId).Scan(&exempt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return decimal.Zero, nil, err
+	}
+	if exempt {
+		metrics.TaxRateResolutions.WithLabelValues("exempt").Inc()
+		return decimal.Zero, entity.NewTaxBreakdown(decimal.Zero, decimal.Zero,
+			decimal.Zero, decimal.Zero), nil
+	}
+
+	compositeRate, taxBreakdown, err := r.findRate(ctx, tenantId, jurisdiction.City)
+	if err == nil {
+		metrics.TaxRateResolutions.WithLabelValues("city").Inc()
+		return compositeRate, taxBreakdown, nil
+	}
+
+	compositeRate, taxBreakdown, err = r.findRate(ctx, tenantId, jurisdiction.County)
+	if err != nil {
+		compositeRate, taxBreakdown, err = r.findRate(ctx, tenantId, "New York State")
+		if err == nil {
+			metrics.TaxRateResolutions.WithLabelValues("state").Inc()
+		}
+		return compositeRate, taxBreakdown, err
+	}
+
+	metrics.TaxRateResolutions.WithLabelValues("county").Inc()
+	return compositeRate, taxBreakdown, nil
+}
+
+func (r *TaxRates) findRate(ctx context.Context, tenantId, jurisdictionName string) (decimal.Decimal,
+	*entity.TaxBreakdown, error) {
+	// The override stores no composite rate, so both branches yield the
+	// components and the sum is taken here.
+	query := `
+		SELECT state_rate, county_rate, city_rate, special_rate
+		FROM (
+			SELECT 0 AS priority, state_rate, county_rate, city_rate, special_rate
+			FROM tenant_rate_overrides
+			WHERE tenant_id = ? AND jurisdiction_name = ?
+			UNION ALL
+			SELECT 1, state_rate, county_rate, city_rate, special_rate
+			FROM tax_rates
+			WHERE jurisdiction_name = ?
+		) rates
+		ORDER BY priority
+		LIMIT 1
+	`
+	var taxBreakdown entity.TaxBreakdown
+	err := r.conn.QueryRowContext(ctx, query, tenantId, jurisdictionName, jurisdictionName).
+		Scan(&taxBreakdown.StateRate, &taxBreakdown.CountyRate, &taxBreakdown.CityRate, &taxBreakdown.SpecialRate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return decimal.Zero, nil, fmt.Errorf("tax rate for %q: %w", jurisdictionName, entity.ErrNotFound)
+	}
+	if err != nil {
+		return decimal.Zero, nil, err
+	}
+	compositeRate := taxBreakdown.StateRate.Add(taxBreakdown.CountyRate).
+		Add(taxBreakdown.CityRate).Add(taxBreakdown.SpecialRate)
+	return compositeRate, &taxBreakdown, nil
+}
+
+func (r *TaxRates) GetSettings(ctx context.Context, tenantId string) (*entity.TaxSettings, error) {
+	settings := &entity.TaxSettings{Overrides: make([]entity.RateOverride, 0)}
+	err := r.conn.QueryRowContext(ctx, "SELECT tax_exempt FROM tenants WHERE id = ?", tenantId).
+		Scan(&settings.Exempt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.QueryContext(ctx, `
+		SELECT jurisdiction_name, state_rate, county_rate, city_rate, special_rate
+		FROM tenant_rate_overrides
+		WHERE tenant_id = ?
+		ORDER BY jurisdiction_name
+	`, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o entity.RateOverride
+		if err := rows.Scan(&o.JurisdictionName, &o.StateRate, &o.CountyRate,
+			&o.CityRate, &o.SpecialRate); err != nil {
+			return nil, err
+		}
+		settings.Overrides = append(settings.Overrides, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// SaveSettings replaces the tenant's exemption flag and its full set of
+// rate overrides.
+func (r *TaxRates) SaveSettings(ctx context.Context, tenantId string, settings *entity.TaxSettings) error {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE tenants SET tax_exempt = ? WHERE id = ?",
+		settings.Exempt, tenantId)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return entity.ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tenant_rate_overrides WHERE tenant_id = ?", tenantId); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO tenant_rate_overrides (
+			tenant_id, jurisdiction_name, state_rate, county_rate, city_rate, special_rate
+		)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	for _, o := range settings.Overrides {
+		_, err := tx.ExecContext(ctx, query, tenantId, o.JurisdictionName,
+			o.StateRate.String(), o.CountyRate.String(), o.CityRate.String(), o.SpecialRate.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
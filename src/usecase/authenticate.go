@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnauthenticated = errors.New(`missing or invalid credentials`)
+	ErrForbidden       = errors.New(`insufficient role for this operation`)
+)
+
+type APIKeys interface {
+	Create(ctx context.Context, key *entity.APIKey, hash string) error
+	FindActiveByHash(ctx context.Context, hash string) (*entity.APIKey, error)
+	List(ctx context.Context) ([]*entity.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+type AuthenticateUseCase struct {
+	apiKeys    APIKeys
+	jwtKey     []byte
+	jwtIssuer  string
+	clockSkew  time.Duration
+	timeSource func() time.Time
+}
+
+// NewAuthenticateUseCase accepts API keys from apiKeys and, when jwtKey is
+// non-empty, HS256-signed JWTs. An empty jwtIssuer skips the "iss" check.
+func NewAuthenticateUseCase(apiKeys APIKeys, jwtKey, jwtIssuer string) *AuthenticateUseCase {
+	return &AuthenticateUseCase{
+		apiKeys:    apiKeys,
+		jwtKey:     []byte(jwtKey),
+		jwtIssuer:  jwtIssuer,
+		clockSkew:  30 * time.Second,
+		timeSource: time.Now,
+	}
+}
+
+func (uc *AuthenticateUseCase) Execute(ctx context.Context, token string) (*entity.Principal, error) {
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	if strings.Count(token, ".") == 2 {
+		if len(uc.jwtKey) == 0 {
+			return nil, ErrUnauthenticated
+		}
+		return uc.verifyJWT(token)
+	}
+
+	key, err := uc.apiKeys.FindActiveByHash(ctx, HashAPIKey(token))
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return nil, ErrUnauthenticated
+		}
+		return nil, err
+	}
+
+	return &entity.Principal{
+		Subject:  "key:" + key.Id.String(),
+		TenantId: key.TenantId,
+		Roles:    key.Roles,
+	}, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Tenant    string   `json:"tenant"`
+	Roles     []string `json:"roles"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+}
+
+func (uc *AuthenticateUseCase) verifyJWT(token string) (*entity.Principal, error) {
+	parts := strings.Split(token, ".")
+
+	headerData, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerData, &header); err != nil || header.Alg != "HS256" {
+		return nil, ErrUnauthenticated
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	mac := hmac.New(sha256.New, uc.jwtKey)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrUnauthenticated
+	}
+
+	claimsData, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(claimsData, &claims); err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	now := uc.timeSource()
+	if claims.ExpiresAt == nil || now.After(time.Unix(*claims.ExpiresAt, 0).Add(uc.clockSkew)) {
+		return nil, ErrUnauthenticated
+	}
+	if claims.NotBefore != nil && now.Add(uc.clockSkew).Before(time.Unix(*claims.NotBefore, 0)) {
+		return nil, ErrUnauthenticated
+	}
+	if uc.jwtIssuer != "" && claims.Issuer != uc.jwtIssuer {
+		return nil, ErrUnauthenticated
+	}
+
+	if claims.Tenant == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	principal := &entity.Principal{
+		Subject:  "jwt:" + claims.Subject,
+		TenantId: claims.Tenant,
+	}
+	for _, r := range claims.Roles {
+		if role, ok := entity.ParseRole(r); ok {
+			principal.Roles = append(principal.Roles, role)
+		}
+	}
+
+	return principal, nil
+}
+
+// HashAPIKey is the only form in which API keys are persisted.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authorize reports whether principal may act with one of roles.
+func Authorize(principal *entity.Principal, roles ...entity.Role) error {
+	if principal == nil {
+		return ErrUnauthenticated
+	}
+	if !principal.HasAnyRole(roles...) {
+		return fmt.Errorf("%w: requires one of %v", ErrForbidden, roles)
+	}
+	return nil
+}
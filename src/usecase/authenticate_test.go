@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/repository/memory"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testJWTKey = "test-signing-key"
+
+var authNow = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// signJWT encodes header and claims and signs them with HMAC-SHA256 under
+// key, whatever alg the header names.
+func signJWT(t *testing.T, key string, header, claims map[string]any) string {
+	t.Helper()
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(header) + "." + encode(claims)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":    "alice",
+		"iss":    "https://issuer.example",
+		"tenant": "brand-b",
+		"roles":  []string{"analyst", "superuser", "importer"},
+		"exp":    authNow.Add(time.Hour).Unix(),
+	}
+}
+
+func newTestAuth(t *testing.T, jwtKey, issuer string) (*AuthenticateUseCase, *ManageAPIKeysUseCase) {
+	t.Helper()
+	rates, err := memory.NewTaxRates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := memory.NewAPIKeys(rates)
+	uc := NewAuthenticateUseCase(keys, jwtKey, issuer)
+	uc.timeSource = func() time.Time { return authNow }
+	return uc, NewManageAPIKeysUseCase(keys)
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	hs256 := map[string]any{"alg": "HS256", "typ": "JWT"}
+	with := func(changes map[string]any) map[string]any {
+		claims := validClaims()
+		for name, value := range changes {
+			if value == nil {
+				delete(claims, name)
+			} else {
+				claims[name] = value
+			}
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name  string
+		token func(t *testing.T) string
+		ok    bool
+	}{
+		{"valid", func(t *testing.T) string { return signJWT(t, testJWTKey, hs256, validClaims()) }, true},
+		{"expired within skew", func(t *testing.T) string {
+			return signJWT(t, testJWTKey, hs256, with(map[string]any{"exp": authNow.Add(-20 * time.Second).Unix()}))
+		}, true},
+		{"expired", func(t *testing.T) string {
+			return signJWT(t, testJWTKey, hs256, with(map[string]any{"exp": authNow.Add(-time.Minute).Unix()}))
+		}, false},
+		{"no expiry", func(t *testing.T) string {
+			return signJWT(t, testJWTKey, hs256, with(map[string]any{"exp": nil}))
+		}, false},
+		{"not yet valid", func(t *testing.T) string {
+			return signJWT(t, testJWTKey, hs256, with(map[string]any{"nbf": authNow.Add(time.Minute).Unix()}))
+		}, false},
+		{"valid from within skew", func(t *testing.T) string {
+			return signJWT(t, testJWTKey, hs256, with(map[string]any{"nbf": authNow.Add(20 * time.Second).Unix()}))
+		}, true},
+		{"other issuer", func(t *testing.T) string {
+			return signJWT(t, testJWTKey, hs256, with(map[string]any{"iss": "https://evil.example"}))
+		}, false},
+		{"no tenant", func(t *testing.T) string {
+			return signJWT(t, testJWTKey, hs256, with(map[string]any{"tenant": nil}))
+		}, false},
+		{"other key", func(t *testing.T) string { return signJWT(t, "other-key", hs256, validClaims()) }, false},
+		{"alg none", func(t *testing.T) string {
+			parts := strings.Split(signJWT(t, testJWTKey, map[string]any{"alg": "none"}, validClaims()), ".")
+			return parts[0] + "." + parts[1] + "."
+		}, false},
+		{"alg HS512", func(t *testing.T) string {
+			return signJWT(t, testJWTKey, map[string]any{"alg": "HS512"}, validClaims())
+		}, false},
+		{"tampered claims", func(t *testing.T) string {
+			token := strings.Split(signJWT(t, testJWTKey, hs256, validClaims()), ".")
+			forged := strings.Split(signJWT(t, testJWTKey, hs256, with(map[string]any{"tenant": "default"})), ".")
+			return token[0] + "." + forged[1] + "." + token[2]
+		}, false},
+		{"not base64", func(t *testing.T) string { return "a.b.c" }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, _ := newTestAuth(t, testJWTKey, "https://issuer.example")
+			principal, err := uc.Execute(context.Background(), tt.token(t))
+			if !tt.ok {
+				if !errors.Is(err, ErrUnauthenticated) {
+					t.Fatalf("Execute = %+v, %v, want %v", principal, err, ErrUnauthenticated)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			// Unknown roles are dropped rather than refusing the token.
+			if principal.Subject != "jwt:alice" || principal.TenantId != "brand-b" ||
+				!slices.Equal(principal.Roles, []entity.Role{entity.RoleAnalyst, entity.RoleImporter}) {
+				t.Errorf("principal = %+v", principal)
+			}
+		})
+	}
+}
+
+func TestAuthenticateJWTWithoutKey(t *testing.T) {
+	uc, _ := newTestAuth(t, "", "")
+	token := signJWT(t, "", map[string]any{"alg": "HS256"}, validClaims())
+	if _, err := uc.Execute(context.Background(), token); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Execute = %v, want %v", err, ErrUnauthenticated)
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	ctx := context.Background()
+	uc, manage := newTestAuth(t, testJWTKey, "")
+
+	plaintext, key, err := manage.Issue(ctx, "dashboard", "brand-b", []string{"analyst", "rate_admin"})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	principal, err := uc.Execute(ctx, plaintext)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if principal.Subject != "key:"+key.Id.String() || principal.TenantId != "brand-b" ||
+		!slices.Equal(principal.Roles, []entity.Role{entity.RoleAnalyst, entity.RoleRateAdmin}) {
+		t.Errorf("principal = %+v", principal)
+	}
+
+	for _, token := range []string{"", "iwk_unknown", plaintext + "x"} {
+		if _, err := uc.Execute(ctx, token); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("Execute(%q) = %v, want %v", token, err, ErrUnauthenticated)
+		}
+	}
+
+	if err := manage.Revoke(ctx, key.Id); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := uc.Execute(ctx, plaintext); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Execute with a revoked key = %v, want %v", err, ErrUnauthenticated)
+	}
+}
+
+func TestIssueAPIKeyValidation(t *testing.T) {
+	_, manage := newTestAuth(t, "", "")
+	tests := []struct {
+		name, keyName, tenant string
+		roles                 []string
+		field                 string
+	}{
+		{"no name", "", "default", []string{"analyst"}, "name"},
+		{"no tenant", "key", "", []string{"analyst"}, "tenant"},
+		{"no roles", "key", "default", nil, "roles"},
+		{"unknown role", "key", "default", []string{"analyst", "root"}, "roles"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := manage.Issue(context.Background(), tt.keyName, tt.tenant, tt.roles)
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Issue = %v, want a validation error", err)
+			}
+			if !slices.ContainsFunc(verr.Fields, func(f FieldError) bool { return f.Field == tt.field }) {
+				t.Errorf("fields = %+v, want %q", verr.Fields, tt.field)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	analyst := &entity.Principal{Subject: "key:a", Roles: []entity.Role{entity.RoleAnalyst}}
+	if err := Authorize(analyst, entity.RoleOrderWriter, entity.RoleAnalyst); err != nil {
+		t.Errorf("Authorize with a held role = %v", err)
+	}
+	if err := Authorize(analyst, entity.RoleRateAdmin); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Authorize without the role = %v, want %v", err, ErrForbidden)
+	}
+	if err := Authorize(nil, entity.RoleAnalyst); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Authorize without a principal = %v, want %v", err, ErrUnauthenticated)
+	}
+}
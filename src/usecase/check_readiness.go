@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"sync"
+	"time"
+)
+
+// HealthCheck probes one dependency. Results of a check with CacheFor set
+// are reused for that long, for probes that are slow or cost quota.
+type HealthCheck struct {
+	Name     string
+	Critical bool
+	CacheFor time.Duration
+	Check    func(ctx context.Context) error
+}
+
+type cachedResult struct {
+	result    entity.HealthCheckResult
+	expiresAt time.Time
+}
+
+// CheckReadinessUseCase runs every health check concurrently, each bounded
+// by timeout, and reports whether the service can take traffic.
+type CheckReadinessUseCase struct {
+	checks  []HealthCheck
+	timeout time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedResult
+}
+
+func NewCheckReadinessUseCase(timeout time.Duration, checks ...HealthCheck) *CheckReadinessUseCase {
+	return &CheckReadinessUseCase{
+		checks:  checks,
+		timeout: timeout,
+		cache:   make(map[string]cachedResult),
+	}
+}
+
+func (uc *CheckReadinessUseCase) Execute(ctx context.Context) *entity.HealthReport {
+	results := make([]entity.HealthCheckResult, len(uc.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range uc.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = uc.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := &entity.HealthReport{Status: entity.HealthOK, Checks: results}
+	for _, result := range results {
+		if result.Status == entity.HealthOK {
+			continue
+		}
+		if result.Critical {
+			report.Status = entity.HealthUnavailable
+		} else if report.Status == entity.HealthOK {
+			report.Status = entity.HealthDegraded
+		}
+	}
+	return report
+}
+
+func (uc *CheckReadinessUseCase) run(ctx context.Context, check HealthCheck) entity.HealthCheckResult {
+	if check.CacheFor > 0 {
+		uc.mu.Lock()
+		cached, ok := uc.cache[check.Name]
+		uc.mu.Unlock()
+		if ok && time.Now().Before(cached.expiresAt) {
+			return cached.result
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+	result := entity.HealthCheckResult{
+		Name:      check.Name,
+		Status:    entity.HealthOK,
+		Critical:  check.Critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = entity.HealthUnavailable
+		result.Error = err.Error()
+	}
+
+	if check.CacheFor > 0 {
+		uc.mu.Lock()
+		uc.cache[check.Name] = cachedResult{result: result, expiresAt: start.Add(check.CacheFor)}
+		uc.mu.Unlock()
+	}
+	return result
+}
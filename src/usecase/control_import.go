@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// ControlImportUseCase pauses, resumes and cancels import jobs. Stopping a
+// job revokes its lease in the database; the runner holding it notices at
+// its next heartbeat, or immediately when it runs in this process, and
+// cancels the job's reader and workers.
+type ControlImportUseCase struct {
+	jobs    ImportJobs
+	orders  Orders
+	storage FileStorage
+	runner  *ImportRunner
+	logger  *slog.Logger
+}
+
+func NewControlImportUseCase(jobs ImportJobs, orders Orders, storage FileStorage,
+	runner *ImportRunner, logger *slog.Logger) *ControlImportUseCase {
+	return &ControlImportUseCase{
+		jobs:    jobs,
+		orders:  orders,
+		storage: storage,
+		runner:  runner,
+		logger:  logger,
+	}
+}
+
+// Pause stops a queued or running job. It keeps its checkpoint, so Resume
+// continues right after the last durably imported chunk.
+func (uc *ControlImportUseCase) Pause(ctx context.Context, id uuid.UUID) (*entity.ImportJob, error) {
+	return uc.transition(ctx, id, []entity.ImportStatus{entity.ImportQueued, entity.ImportRunning},
+		entity.ImportPaused, false)
+}
+
+func (uc *ControlImportUseCase) Resume(ctx context.Context, id uuid.UUID) (*entity.ImportJob, error) {
+	return uc.transition(ctx, id, []entity.ImportStatus{entity.ImportPaused}, entity.ImportQueued, false)
+}
+
+// Cancel stops a job for good. With rollback, the orders it already
+// inserted are deleted as well.
+func (uc *ControlImportUseCase) Cancel(ctx context.Context, id uuid.UUID, rollback bool) (*entity.ImportJob, error) {
+	job, err := uc.transition(ctx, id, []entity.ImportStatus{entity.ImportQueued,
+		entity.ImportRunning, entity.ImportPaused}, entity.ImportCancelled, rollback)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.storage.Delete(ctx, job.FileKey); err != nil {
+		uc.logger.WarnContext(ctx, "Failed to delete import upload", "import_id", job.Id, "error", err)
+	}
+
+	if rollback {
+		// A runner on another replica may still commit one more chunk
+		// before it notices; it rolls back again once it has stopped.
+		if err := rollbackImport(ctx, uc.orders, job, uc.logger); err != nil {
+			return nil, err
+		}
+	}
+
+	return job, nil
+}
+
+func (uc *ControlImportUseCase) transition(ctx context.Context, id uuid.UUID, from []entity.ImportStatus,
+	to entity.ImportStatus, rollback bool) (*entity.ImportJob, error) {
+	tenantId := tenantFromContext(ctx)
+
+	job, err := uc.jobs.Transition(ctx, tenantId, id, from, to, rollback)
+	if errors.Is(err, entity.ErrNotFound) {
+		// Tell a missing job apart from one in the wrong status.
+		if _, err := uc.jobs.Get(ctx, tenantId, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidTransition
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	uc.runner.interrupt(id)
+
+	return job, nil
+}
+
+func rollbackImport(ctx context.Context, orders Orders, job *entity.ImportJob, logger *slog.Logger) error {
+	deleted, err := orders.DeleteByImport(ctx, job.TenantId, job.Id)
+	if err != nil {
+		return err
+	}
+	logger.InfoContext(ctx, "Import rolled back", "import_id", job.Id, "orders_deleted", deleted)
+	return nil
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// stallingGeocoder places points in New York until it has geocoded after
+// rows, then holds every call until the job is stopped or release is
+// called.
+type stallingGeocoder struct {
+	after    int64
+	geocoded atomic.Int64
+	stalled  chan struct{}
+	stall    sync.Once
+	released chan struct{}
+}
+
+func newStallingGeocoder(after int64) *stallingGeocoder {
+	return &stallingGeocoder{after: after, stalled: make(chan struct{}), released: make(chan struct{})}
+}
+
+func (g *stallingGeocoder) GetJurisdiction(ctx context.Context, latitude, longitude float64) (*entity.Jurisdiction, error) {
+	if g.geocoded.Add(1) > g.after {
+		g.stall.Do(func() { close(g.stalled) })
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-g.released:
+		}
+	}
+	return inNewYork(ctx, latitude, longitude)
+}
+
+func (g *stallingGeocoder) release() { close(g.released) }
+
+// runControlled starts a runner on q and returns the use case controlling
+// its jobs and a function that shuts the runner down.
+func runControlled(t *testing.T, q *testQueue, geocoder GeocodingService) (*ControlImportUseCase, func()) {
+	t.Helper()
+	runner := q.runner(geocoder)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runner.Run(ctx, 1, time.Second)
+	}()
+	stop := func() {
+		cancel()
+		<-done
+	}
+	t.Cleanup(stop)
+	return NewControlImportUseCase(q.jobs, q.orders, q.files, runner, slog.New(slog.DiscardHandler)), stop
+}
+
+func importedCount(t *testing.T, q *testQueue) int {
+	t.Helper()
+	n := 0
+	err := q.orders.Stream(context.Background(), entity.ListParams{TenantId: entity.DefaultTenant},
+		func(*entity.Order) error {
+			n++
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	return n
+}
+
+func TestPauseAndResumeImport(t *testing.T) {
+	const rows = 1200
+	ctx := context.Background()
+	q := newTestQueue(t)
+	job := q.enqueue(t, rows)
+
+	// The job stalls in its second chunk, after the first was checkpointed.
+	geocoder := newStallingGeocoder(importChunkSize + 100)
+	control, _ := runControlled(t, q, geocoder)
+	<-geocoder.stalled
+
+	paused, err := control.Pause(ctx, job.Id)
+	if err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	if paused.Status != entity.ImportPaused || paused.CheckpointRow != importChunkSize+1 {
+		t.Fatalf("paused job is %s at row %d, want %s at row %d",
+			paused.Status, paused.CheckpointRow, entity.ImportPaused, importChunkSize+1)
+	}
+	if _, err := control.Pause(ctx, job.Id); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("second Pause = %v, want %v", err, ErrInvalidTransition)
+	}
+
+	// Nothing past the checkpoint is written while the job is paused,
+	// and the runner does not claim it again.
+	geocoder.release()
+	time.Sleep(50 * time.Millisecond)
+	if current := q.waitForStatus(t, job.Id, entity.ImportPaused); current.CheckpointRow != importChunkSize+1 {
+		t.Errorf("paused job moved on to row %d", current.CheckpointRow)
+	}
+	if n := importedCount(t, q); n != importChunkSize {
+		t.Errorf("%d orders imported while paused, want %d", n, importChunkSize)
+	}
+
+	resumed, err := control.Resume(ctx, job.Id)
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if resumed.Status != entity.ImportQueued {
+		t.Errorf("resumed job is %s, want %s", resumed.Status, entity.ImportQueued)
+	}
+
+	completed := q.waitForStatus(t, job.Id, entity.ImportCompleted)
+	if completed.RowsRead != rows || completed.RowsImported != rows || completed.RowsFailed != 0 {
+		t.Errorf("completed job = %+v, want all %d rows imported", completed.Progress(), rows)
+	}
+	assertImportedOnce(t, q.orders, rows)
+	if _, err := control.Resume(ctx, job.Id); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Resume of a completed job = %v, want %v", err, ErrInvalidTransition)
+	}
+}
+
+func TestCancelImportWithRollback(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t)
+	job := q.enqueue(t, 1200)
+
+	geocoder := newStallingGeocoder(importChunkSize + 100)
+	control, stop := runControlled(t, q, geocoder)
+	<-geocoder.stalled
+
+	cancelled, err := control.Cancel(ctx, job.Id, true)
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if cancelled.Status != entity.ImportCancelled || !cancelled.RolledBack || cancelled.FinishedAt == nil {
+		t.Errorf("cancelled job = %+v, want cancelled, rolled back and finished", cancelled)
+	}
+	stop()
+
+	if n := importedCount(t, q); n != 0 {
+		t.Errorf("%d orders left after the rollback, want none", n)
+	}
+	if _, err := q.files.Open(ctx, job.FileKey); err == nil {
+		t.Error("upload was kept after the import was cancelled")
+	}
+	if _, err := control.Resume(ctx, job.Id); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Resume of a cancelled job = %v, want %v", err, ErrInvalidTransition)
+	}
+}
+
+func TestCancelImportKeepsOrders(t *testing.T) {
+	ctx := context.Background()
+	q := newTestQueue(t)
+	job := q.enqueue(t, 1200)
+
+	geocoder := newStallingGeocoder(importChunkSize + 100)
+	control, stop := runControlled(t, q, geocoder)
+	<-geocoder.stalled
+
+	if _, err := control.Cancel(ctx, job.Id, false); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	stop()
+
+	if n := importedCount(t, q); n != importChunkSize {
+		t.Errorf("%d orders left after cancelling, want the %d of the first chunk", n, importChunkSize)
+	}
+}
+
+func TestControlImportRefusals(t *testing.T) {
+	q := newTestQueue(t)
+	control := NewControlImportUseCase(q.jobs, q.orders, q.files, q.runner(inNewYork),
+		slog.New(slog.DiscardHandler))
+	job := q.enqueue(t, 1)
+
+	if _, err := control.Pause(context.Background(), uuid.New()); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("Pause of an unknown job = %v, want %v", err, entity.ErrNotFound)
+	}
+	// Jobs of other tenants are not found either.
+	other := entity.ContextWithPrincipal(context.Background(), &entity.Principal{TenantId: "brand-b"})
+	if _, err := control.Cancel(other, job.Id, true); !errors.Is(err, entity.ErrNotFound) {
+		t.Errorf("Cancel from another tenant = %v, want %v", err, entity.ErrNotFound)
+	}
+	if _, err := control.Resume(context.Background(), job.Id); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Resume of a queued job = %v, want %v", err, ErrInvalidTransition)
+	}
+}
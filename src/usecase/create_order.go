@@ -4,28 +4,37 @@ import (
 	"InstantWellnessKits/src/entity"
 	"context"
 	"errors"
-	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
 var (
 	ErrFailedParsingTimestamp = errors.New(`failed parsing timestamp`)
+	ErrInvalidLatitude        = errors.New(`latitude must be between -90 and 90`)
+	ErrInvalidLongitude       = errors.New(`longitude must be between -180 and 180`)
+	ErrInvalidSubtotal        = errors.New(`subtotal must not be negative`)
+	ErrMissingField           = errors.New(`field is required`)
 )
 
 type GeocodingService interface {
-	GetJurisdiction(latitude, longitude float64) (*entity.Jurisdiction, error)
+	GetJurisdiction(ctx context.Context, latitude, longitude float64) (*entity.Jurisdiction, error)
 }
 
 type Orders interface {
 	Create(ctx context.Context, order *entity.Order) (*entity.Order, error)
 	List(ctx context.Context, params entity.ListParams) (*entity.ListResult, error)
 	CreateBatch(ctx context.Context, orders []*entity.Order) error
+	DeleteByImport(ctx context.Context, tenantId string, importJobId uuid.UUID) (int64, error)
+	Stream(ctx context.Context, params entity.ListParams, fn func(*entity.Order) error) error
+	UpdateTaxes(ctx context.Context, tenantId string, orders []*entity.Order) error
+	Timeseries(ctx context.Context, params entity.TimeseriesParams) ([]*entity.TimeseriesPoint, error)
+	Heatmap(ctx context.Context, filter entity.ListParams, grid entity.Grid) ([]*entity.HeatmapCell, error)
 }
 
 type TaxRates interface {
-	Get(ctx context.Context, jurisdiction *entity.Jurisdiction) (decimal.Decimal,
+	Get(ctx context.Context, tenantId string, jurisdiction *entity.Jurisdiction) (decimal.Decimal,
 		*entity.TaxBreakdown, error)
 }
 
@@ -33,29 +42,51 @@ type CreateOrderUseCase struct {
 	geocodingService GeocodingService
 	orders           Orders
 	taxRates         TaxRates
+	location         *time.Location
 }
 
 func NewCreateOrderUseCase(geocodingService GeocodingService,
-	orders Orders, taxRates TaxRates) *CreateOrderUseCase {
+	orders Orders, taxRates TaxRates, location *time.Location) *CreateOrderUseCase {
 	return &CreateOrderUseCase{
 		geocodingService: geocodingService,
 		orders:           orders,
 		taxRates:         taxRates,
+		location:         location,
 	}
 }
 
 func (uc *CreateOrderUseCase) Execute(ctx context.Context,
 	latitude, longitude float64, subtotal int, timestamp string) (*entity.Order, error) {
-	juris, err := uc.geocodingService.GetJurisdiction(latitude, longitude)
+	ctx, span := tracer.Start(ctx, "CreateOrderUseCase.Execute")
+	order, err := uc.execute(ctx, latitude, longitude, subtotal, timestamp)
+	endSpan(span, err)
+	return order, err
+}
+
+func (uc *CreateOrderUseCase) execute(ctx context.Context,
+	latitude, longitude float64, subtotal int, timestamp string) (*entity.Order, error) {
+	verr := &ValidationError{}
+	if latitude < -90 || latitude > 90 {
+		verr.Add("latitude", ErrInvalidLatitude)
+	}
+	if longitude < -180 || longitude > 180 {
+		verr.Add("longitude", ErrInvalidLongitude)
+	}
+	if subtotal < 0 {
+		verr.Add("subtotal", ErrInvalidSubtotal)
+	}
+	parsedTimestamp, err := entity.ParseTimestamp(timestamp, uc.location)
 	if err != nil {
+		verr.Add("timestamp", ErrFailedParsingTimestamp)
+	}
+	if err := verr.OrNil(); err != nil {
 		return nil, err
 	}
 
-	if juris.State != "New York" {
-		return nil, fmt.Errorf("delivery location is outside New York State (got: %s)", juris.State)
-	}
+	tenantId := tenantFromContext(ctx)
 
-	compositeTaxRate, taxBreakdown, err := uc.taxRates.Get(ctx, juris)
+	juris, compositeTaxRate, taxBreakdown, err := resolveTax(ctx,
+		uc.geocodingService, uc.taxRates, tenantId, latitude, longitude)
 	if err != nil {
 		return nil, err
 	}
@@ -67,14 +98,10 @@ func (uc *CreateOrderUseCase) Execute(ctx context.Context,
 
 	totalAmount := decimalSubtotal.Add(taxAmount)
 
-	parsedTimestamp, err := time.Parse(time.DateTime, timestamp)
-	if err != nil {
-		return nil, ErrFailedParsingTimestamp
-	}
-
 	order := entity.NewOrder(latitude, longitude,
 		decimalSubtotal, compositeTaxRate, taxAmount, totalAmount,
 		taxBreakdown, juris, parsedTimestamp)
+	order.TenantId = tenantId
 
 	return uc.orders.Create(ctx, order)
 }
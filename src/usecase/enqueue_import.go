@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrEmptyUpload = errors.New(`uploaded file is empty`)
+
+// FileStorage keeps uploaded files until the import that reads them has
+// finished. It has to be shared by every replica that runs imports.
+type FileStorage interface {
+	Save(ctx context.Context, key string, r io.Reader) (int64, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type ImportJobs interface {
+	Create(ctx context.Context, job *entity.ImportJob) error
+	Get(ctx context.Context, tenantId string, id uuid.UUID) (*entity.ImportJob, error)
+	Claim(ctx context.Context, workerId string, lease time.Duration) (*entity.ImportJob, error)
+	Checkpoint(ctx context.Context, id uuid.UUID, workerId string, progress entity.ImportProgress,
+		lease time.Duration) error
+	Heartbeat(ctx context.Context, id uuid.UUID, workerId string, lease time.Duration) error
+	Finish(ctx context.Context, id uuid.UUID, workerId string, status entity.ImportStatus, message string) error
+	Release(ctx context.Context, id uuid.UUID, workerId string) error
+	Postpone(ctx context.Context, id uuid.UUID, workerId string, until time.Time, message string) error
+	Transition(ctx context.Context, tenantId string, id uuid.UUID, from []entity.ImportStatus,
+		status entity.ImportStatus, rollback bool) (*entity.ImportJob, error)
+}
+
+// EnqueueImportUseCase stores an uploaded file and queues a job for it.
+// The job is picked up by an ImportRunner, possibly on another replica.
+type EnqueueImportUseCase struct {
+	storage FileStorage
+	jobs    ImportJobs
+	logger  *slog.Logger
+}
+
+func NewEnqueueImportUseCase(storage FileStorage, jobs ImportJobs, logger *slog.Logger) *EnqueueImportUseCase {
+	return &EnqueueImportUseCase{
+		storage: storage,
+		jobs:    jobs,
+		logger:  logger,
+	}
+}
+
+// Execute queues the upload r. contentType is the one declared for the
+// file, if any; generic types leave the format to be detected later.
+func (uc *EnqueueImportUseCase) Execute(ctx context.Context, fileName, contentType string, r io.Reader,
+	dryRun bool) (*entity.ImportJob, error) {
+	job := entity.NewImportJob(tenantFromContext(ctx), fileName, FormatFromContentType(contentType), dryRun)
+
+	size, err := uc.storage.Save(ctx, job.FileKey, r)
+	if err != nil {
+		return nil, fmt.Errorf("storing upload: %w", err)
+	}
+	if size == 0 {
+		uc.discard(ctx, job)
+		return nil, ErrEmptyUpload
+	}
+
+	if err := uc.jobs.Create(ctx, job); err != nil {
+		uc.discard(ctx, job)
+		return nil, fmt.Errorf("queueing import: %w", err)
+	}
+
+	return job, nil
+}
+
+func (uc *EnqueueImportUseCase) discard(ctx context.Context, job *entity.ImportJob) {
+	if err := uc.storage.Delete(context.WithoutCancel(ctx), job.FileKey); err != nil {
+		uc.logger.WarnContext(ctx, "Failed to delete import upload", "import_id", job.Id, "error", err)
+	}
+}
+
+type GetImportUseCase struct {
+	jobs ImportJobs
+}
+
+func NewGetImportUseCase(jobs ImportJobs) *GetImportUseCase {
+	return &GetImportUseCase{
+		jobs: jobs,
+	}
+}
+
+func (uc *GetImportUseCase) Execute(ctx context.Context, id uuid.UUID) (*entity.ImportJob, error) {
+	return uc.jobs.Get(ctx, tenantFromContext(ctx), id)
+}
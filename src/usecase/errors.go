@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrOutOfState           = errors.New(`delivery location is outside New York State`)
+	ErrJurisdictionNotFound = errors.New(`tax jurisdiction not found`)
+	ErrGeocodingUnavailable = errors.New(`geocoding service unavailable`)
+	ErrValidation           = errors.New(`validation failed`)
+	ErrInvalidTransition    = errors.New(`import job cannot make that transition in its current status`)
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	err     error
+}
+
+// ValidationError lists every invalid input field. It matches ErrValidation
+// and any sentinel error attached to one of its fields.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Add(field string, err error) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: err.Error(), err: err})
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+	return ErrValidation.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		if f.err != nil {
+			errs = append(errs, f.err)
+		}
+	}
+	return errs
+}
+
+// OrNil returns e only when at least one field failed.
+func (e *ValidationError) OrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// InvalidField returns a ValidationError for a single field.
+func InvalidField(field string, err error) error {
+	v := &ValidationError{}
+	v.Add(field, err)
+	return v
+}
@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+)
+
+type ExportOrdersUseCase struct {
+	orders Orders
+}
+
+func NewExportOrdersUseCase(orders Orders) *ExportOrdersUseCase {
+	return &ExportOrdersUseCase{
+		orders: orders,
+	}
+}
+
+func (uc *ExportOrdersUseCase) Execute(ctx context.Context, params entity.ListParams,
+	fn func(*entity.Order) error) error {
+	params.TenantId = tenantFromContext(ctx)
+	return uc.orders.Stream(ctx, params, fn)
+}
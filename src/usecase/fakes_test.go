@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+// geocoderFunc adapts a function to GeocodingService.
+type geocoderFunc func(ctx context.Context, latitude, longitude float64) (*entity.Jurisdiction, error)
+
+func (f geocoderFunc) GetJurisdiction(ctx context.Context, latitude, longitude float64) (*entity.Jurisdiction, error) {
+	return f(ctx, latitude, longitude)
+}
+
+// inNewYork places every point in New York City.
+var inNewYork = geocoderFunc(func(context.Context, float64, float64) (*entity.Jurisdiction, error) {
+	return &entity.Jurisdiction{State: "New York", County: "New York County", City: "New York"}, nil
+})
+
+// flatRate taxes every jurisdiction at 8.875%.
+type flatRate struct{}
+
+func (flatRate) Get(context.Context, string, *entity.Jurisdiction) (decimal.Decimal, *entity.TaxBreakdown, error) {
+	rate := decimal.RequireFromString("0.08875")
+	return rate, entity.NewTaxBreakdown(decimal.RequireFromString("0.04"), decimal.RequireFromString("0.04875"),
+		decimal.Zero, decimal.Zero), nil
+}
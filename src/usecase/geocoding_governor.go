@@ -0,0 +1,268 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var ErrGeocodingQuotaExceeded = fmt.Errorf("%w: daily quota exhausted", ErrGeocodingUnavailable)
+
+// QuotaExceededError is ErrGeocodingQuotaExceeded together with the time
+// the quota resets.
+type QuotaExceededError struct {
+	ResetAt time.Time
+}
+
+func (e *QuotaExceededError) Error() string {
+	return ErrGeocodingQuotaExceeded.Error()
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrGeocodingQuotaExceeded
+}
+
+type geocodingPriority int
+
+const (
+	priorityInteractive geocodingPriority = iota
+	priorityBulk
+)
+
+type priorityKey struct{}
+
+// withBulkPriority marks geocoding calls made with ctx as background work
+// that yields to interactive requests.
+func withBulkPriority(ctx context.Context) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priorityBulk)
+}
+
+func priorityFromContext(ctx context.Context) geocodingPriority {
+	if p, ok := ctx.Value(priorityKey{}).(geocodingPriority); ok {
+		return p
+	}
+	return priorityInteractive
+}
+
+type geocodingTicket struct {
+	ctx   context.Context
+	ready chan error
+}
+
+// GeocodingGovernor enforces one process-wide geocoding budget: at most
+// perSecond calls per second and dailyCap calls per quota day, shared by
+// every caller. Interactive calls are served before bulk ones. Once the
+// daily cap is hit, every call fails with a QuotaExceededError until the
+// quota resets, so that imports can be postponed rather than hold a
+// worker for the rest of the day.
+type GeocodingGovernor struct {
+	next      GeocodingService
+	interval  time.Duration
+	dailyCap  int
+	quotaZone *time.Location
+	logger    *slog.Logger
+
+	interactive chan *geocodingTicket
+	bulk        chan *geocodingTicket
+	stop        chan struct{}
+	stopOnce    sync.Once
+	done        chan struct{}
+
+	mu      sync.Mutex
+	used    int
+	resetAt time.Time
+}
+
+// NewGeocodingGovernor wraps next; perSecond must be positive, see
+// config.New. A dailyCap of zero disables the daily limit; quotaZone is the
+// timezone whose midnight resets the quota.
+func NewGeocodingGovernor(next GeocodingService, perSecond float64, dailyCap int,
+	quotaZone *time.Location, logger *slog.Logger) *GeocodingGovernor {
+	g := &GeocodingGovernor{
+		next:        next,
+		interval:    time.Duration(float64(time.Second) / perSecond),
+		dailyCap:    dailyCap,
+		quotaZone:   quotaZone,
+		logger:      logger,
+		interactive: make(chan *geocodingTicket),
+		bulk:        make(chan *geocodingTicket),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	g.resetAt = nextMidnight(time.Now(), quotaZone)
+
+	go g.dispatch()
+
+	return g
+}
+
+func (g *GeocodingGovernor) GetJurisdiction(ctx context.Context,
+	latitude, longitude float64) (*entity.Jurisdiction, error) {
+	// The wait for a slot shows up in traces of throttled calls.
+	_, span := tracer.Start(ctx, "GeocodingGovernor.acquire")
+	err := g.acquire(ctx)
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return g.next.GetJurisdiction(ctx, latitude, longitude)
+}
+
+// geocodingPinger is a GeocodingService that can check it is reachable.
+type geocodingPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping probes the wrapped service for a readiness check. The probe is a
+// geocoding call like any other, so it waits for a slot, counts against
+// the daily cap and fails with a QuotaExceededError once that is used up.
+// A service that cannot be probed passes.
+func (g *GeocodingGovernor) Ping(ctx context.Context) error {
+	pinger, ok := g.next.(geocodingPinger)
+	if !ok {
+		return nil
+	}
+	if err := g.acquire(ctx); err != nil {
+		return err
+	}
+	return pinger.Ping(ctx)
+}
+
+// Close stops the dispatcher. Pending and later calls fail.
+func (g *GeocodingGovernor) Close() {
+	g.stopOnce.Do(func() { close(g.stop) })
+	<-g.done
+}
+
+func (g *GeocodingGovernor) acquire(ctx context.Context) error {
+	if g.exhausted(time.Now()) {
+		return g.quotaExceeded()
+	}
+	queue := g.bulk
+	if priorityFromContext(ctx) == priorityInteractive {
+		queue = g.interactive
+	}
+
+	ticket := &geocodingTicket{ctx: ctx, ready: make(chan error, 1)}
+	select {
+	case queue <- ticket:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-g.stop:
+		return ErrGeocodingUnavailable
+	}
+
+	select {
+	case err := <-ticket.ready:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (g *GeocodingGovernor) dispatch() {
+	defer close(g.done)
+
+	next := time.Now()
+	for {
+		if wait := time.Until(next); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-g.stop:
+				return
+			}
+		}
+
+		if g.exhausted(time.Now()) {
+			if !g.waitForReset() {
+				return
+			}
+			continue
+		}
+
+		var ticket *geocodingTicket
+		select {
+		case ticket = <-g.interactive:
+		default:
+			select {
+			case ticket = <-g.interactive:
+			case ticket = <-g.bulk:
+			case <-g.stop:
+				return
+			}
+		}
+
+		// The caller gave up while queued; keep the slot for someone else.
+		if ticket.ctx.Err() != nil {
+			continue
+		}
+
+		g.mu.Lock()
+		g.used++
+		g.mu.Unlock()
+
+		ticket.ready <- nil
+		next = time.Now().Add(g.interval)
+	}
+}
+
+// waitForReset turns callers away until the quota day rolls over. It
+// returns false on Close.
+func (g *GeocodingGovernor) waitForReset() bool {
+	g.mu.Lock()
+	resetAt := g.resetAt
+	g.mu.Unlock()
+
+	g.logger.Warn("Geocoding daily quota exhausted",
+		"daily_cap", g.dailyCap, "reset_at", resetAt)
+
+	timer := time.NewTimer(time.Until(resetAt))
+	defer timer.Stop()
+
+	for {
+		select {
+		case ticket := <-g.interactive:
+			ticket.ready <- &QuotaExceededError{ResetAt: resetAt}
+		case ticket := <-g.bulk:
+			ticket.ready <- &QuotaExceededError{ResetAt: resetAt}
+		case <-timer.C:
+			g.exhausted(time.Now())
+			g.logger.Info("Geocoding daily quota reset, resuming")
+			return true
+		case <-g.stop:
+			return false
+		}
+	}
+}
+
+// exhausted reports whether the daily cap is used up, rolling the quota
+// over first if its day has ended.
+func (g *GeocodingGovernor) exhausted(now time.Time) bool {
+	if g.dailyCap <= 0 {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !now.Before(g.resetAt) {
+		g.used = 0
+		g.resetAt = nextMidnight(now, g.quotaZone)
+	}
+
+	return g.used >= g.dailyCap
+}
+
+func (g *GeocodingGovernor) quotaExceeded() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return &QuotaExceededError{ResetAt: g.resetAt}
+}
+
+func nextMidnight(now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// pingableGeocoder counts the probes of a geocoder in New York.
+type pingableGeocoder struct {
+	geocoderFunc
+	pings int
+}
+
+func (g *pingableGeocoder) Ping(context.Context) error {
+	g.pings++
+	return nil
+}
+
+func TestGovernorPingSpendsQuota(t *testing.T) {
+	geocoder := &pingableGeocoder{geocoderFunc: inNewYork}
+	governor := NewGeocodingGovernor(geocoder, 1000, 2, time.UTC, slog.New(slog.DiscardHandler))
+	defer governor.Close()
+	ctx := context.Background()
+
+	if err := governor.Ping(ctx); err != nil || geocoder.pings != 1 {
+		t.Fatalf("Ping = %v after %d probes, want one successful probe", err, geocoder.pings)
+	}
+	if _, err := governor.GetJurisdiction(ctx, 40.7, -74); err != nil {
+		t.Fatalf("GetJurisdiction: %v", err)
+	}
+
+	// The probe and the lookup used up the daily cap of two calls.
+	var quotaErr *QuotaExceededError
+	if err := governor.Ping(ctx); !errors.As(err, &quotaErr) {
+		t.Errorf("Ping = %v, want a QuotaExceededError", err)
+	}
+	if geocoder.pings != 1 {
+		t.Errorf("geocoder probed %d times, want once", geocoder.pings)
+	}
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"errors"
+	"math"
+)
+
+const (
+	defaultCellSize     = 0.05
+	minCellSize         = 0.001
+	maxGeohashPrecision = 9
+	geohashAlphabet     = "0123456789bcdefghjkmnpqrstuvwxyz"
+)
+
+// Rough bounding box of New York State, used to flag cells whose orders
+// could not have been delivered inside the state.
+const (
+	newYorkMinLatitude  = 40.4774
+	newYorkMaxLatitude  = 45.0159
+	newYorkMinLongitude = -79.7624
+	newYorkMaxLongitude = -71.7517
+)
+
+var (
+	ErrInvalidCellSize = errors.New(`invalid cell size`)
+	ErrInvalidGeohash  = errors.New(`invalid geohash precision, expected 1 to 9`)
+)
+
+type HeatmapUseCase struct {
+	orders Orders
+}
+
+func NewHeatmapUseCase(orders Orders) *HeatmapUseCase {
+	return &HeatmapUseCase{
+		orders: orders,
+	}
+}
+
+func (uc *HeatmapUseCase) Execute(ctx context.Context,
+	params entity.HeatmapParams) ([]*entity.HeatmapCell, error) {
+	grid, err := heatmapGrid(params)
+	if err != nil {
+		return nil, err
+	}
+
+	params.Filter.TenantId = tenantFromContext(ctx)
+
+	cells, err := uc.orders.Heatmap(ctx, params.Filter, grid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cell := range cells {
+		cell.MinLatitude = grid.OriginLatitude + float64(cell.Row)*grid.LatitudeStep
+		cell.MinLongitude = grid.OriginLongitude + float64(cell.Column)*grid.LongitudeStep
+		cell.MaxLatitude = cell.MinLatitude + grid.LatitudeStep
+		cell.MaxLongitude = cell.MinLongitude + grid.LongitudeStep
+
+		if params.GeohashPrecision > 0 {
+			cell.Geohash = encodeGeohash(
+				(cell.MinLatitude+cell.MaxLatitude)/2,
+				(cell.MinLongitude+cell.MaxLongitude)/2,
+				params.GeohashPrecision)
+		}
+
+		cell.OutsideState = cell.MaxLatitude < newYorkMinLatitude ||
+			cell.MinLatitude > newYorkMaxLatitude ||
+			cell.MaxLongitude < newYorkMinLongitude ||
+			cell.MinLongitude > newYorkMaxLongitude
+	}
+
+	return cells, nil
+}
+
+// heatmapGrid returns either a square grid of CellSize degrees or, when a
+// geohash precision is requested, the lattice whose cells coincide exactly
+// with geohash cells of that length.
+func heatmapGrid(params entity.HeatmapParams) (entity.Grid, error) {
+	if params.GeohashPrecision != 0 {
+		if params.GeohashPrecision < 1 || params.GeohashPrecision > maxGeohashPrecision {
+			return entity.Grid{}, InvalidField("geohash", ErrInvalidGeohash)
+		}
+
+		bits := 5 * params.GeohashPrecision
+		lonBits := (bits + 1) / 2
+		latBits := bits / 2
+
+		return entity.Grid{
+			OriginLatitude:  -90,
+			OriginLongitude: -180,
+			LatitudeStep:    180 / math.Exp2(float64(latBits)),
+			LongitudeStep:   360 / math.Exp2(float64(lonBits)),
+		}, nil
+	}
+
+	size := params.CellSize
+	if size == 0 {
+		size = defaultCellSize
+	}
+	// NaN passes both comparisons, and the database cannot floor it.
+	if math.IsNaN(size) || math.IsInf(size, 0) || size < minCellSize || size > 180 {
+		return entity.Grid{}, InvalidField("cellSize", ErrInvalidCellSize)
+	}
+
+	return entity.Grid{
+		OriginLatitude:  -90,
+		OriginLongitude: -180,
+		LatitudeStep:    size,
+		LongitudeStep:   size,
+	}, nil
+}
+
+func encodeGeohash(latitude, longitude float64, precision int) string {
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+
+	hash := make([]byte, 0, precision)
+	bit, ch := 0, 0
+	even := true
+	for len(hash) < precision {
+		if even {
+			mid := (minLon + maxLon) / 2
+			if longitude >= mid {
+				ch |= 1 << (4 - bit)
+				minLon = mid
+			} else {
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if latitude >= mid {
+				ch |= 1 << (4 - bit)
+				minLat = mid
+			} else {
+				maxLat = mid
+			}
+		}
+		even = !even
+
+		if bit < 4 {
+			bit++
+		} else {
+			hash = append(hash, geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return string(hash)
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestHeatmapGridRejectsInvalidCellSizes(t *testing.T) {
+	for _, size := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), -1, minCellSize / 2, 180.5} {
+		_, err := heatmapGrid(entity.HeatmapParams{CellSize: size})
+		if !errors.Is(err, ErrInvalidCellSize) {
+			t.Errorf("cell size %v: err = %v, want ErrInvalidCellSize", size, err)
+		}
+	}
+}
+
+func TestHeatmapGrid(t *testing.T) {
+	grid, err := heatmapGrid(entity.HeatmapParams{CellSize: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grid.LatitudeStep != 0.5 || grid.LongitudeStep != 0.5 {
+		t.Errorf("steps = %v, %v; want 0.5", grid.LatitudeStep, grid.LongitudeStep)
+	}
+
+	// Geohash length 5 is 25 bits: 13 of longitude, 12 of latitude.
+	grid, err = heatmapGrid(entity.HeatmapParams{GeohashPrecision: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grid.LongitudeStep != 360.0/8192 || grid.LatitudeStep != 180.0/4096 {
+		t.Errorf("steps = %v, %v; want %v, %v", grid.LatitudeStep, grid.LongitudeStep, 180.0/4096, 360.0/8192)
+	}
+}
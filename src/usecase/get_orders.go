@@ -3,18 +3,25 @@ package usecase
 import (
 	"InstantWellnessKits/src/entity"
 	"context"
+	"time"
 )
 
 type ListOrdersUseCase struct {
-	orders Orders
+	orders   Orders
+	location *time.Location
 }
 
-func NewListOrdersUseCase(orders Orders) *ListOrdersUseCase {
+func NewListOrdersUseCase(orders Orders, location *time.Location) *ListOrdersUseCase {
 	return &ListOrdersUseCase{
-		orders: orders,
+		orders:   orders,
+		location: location,
 	}
 }
 
 func (uc *ListOrdersUseCase) Execute(ctx context.Context, params entity.ListParams) (*entity.ListResult, error) {
+	params.TenantId = tenantFromContext(ctx)
+	if params.Last24hFrom.IsZero() {
+		params.Last24hFrom = time.Now().In(uc.location).Add(-24 * time.Hour)
+	}
 	return uc.orders.List(ctx, params)
-}
\ No newline at end of file
+}
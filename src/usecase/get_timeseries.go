@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	defaultTimeseriesRange = 30 * 24 * time.Hour
+	maxTimeseriesBuckets   = 5000
+)
+
+var (
+	ErrInvalidInterval  = errors.New(`invalid interval, expected hour, day, week or month`)
+	ErrInvalidTimeRange = errors.New(`"from" must be before "to"`)
+	ErrTooManyBuckets   = errors.New(`time range is too large for the interval`)
+)
+
+type TimeseriesUseCase struct {
+	orders   Orders
+	location *time.Location
+}
+
+func NewTimeseriesUseCase(orders Orders, location *time.Location) *TimeseriesUseCase {
+	return &TimeseriesUseCase{
+		orders:   orders,
+		location: location,
+	}
+}
+
+func (uc *TimeseriesUseCase) Execute(ctx context.Context,
+	params entity.TimeseriesParams) (*entity.TimeseriesResult, error) {
+	if params.Interval == "" {
+		params.Interval = entity.IntervalDay
+	}
+
+	var step time.Duration
+	switch params.Interval {
+	case entity.IntervalHour:
+		step = time.Hour
+	case entity.IntervalDay:
+		step = 24 * time.Hour
+	case entity.IntervalWeek:
+		step = 7 * 24 * time.Hour
+	case entity.IntervalMonth:
+		step = 28 * 24 * time.Hour
+	default:
+		return nil, InvalidField("interval", ErrInvalidInterval)
+	}
+
+	if params.To.IsZero() {
+		params.To = time.Now()
+	}
+	if params.From.IsZero() {
+		params.From = params.To.Add(-defaultTimeseriesRange)
+	}
+	if !params.From.Before(params.To) {
+		return nil, InvalidField("from", ErrInvalidTimeRange)
+	}
+	if params.To.Sub(params.From)/step > maxTimeseriesBuckets {
+		return nil, InvalidField("interval", ErrTooManyBuckets)
+	}
+
+	params.Location = uc.location
+	params.TenantId = tenantFromContext(ctx)
+
+	points, err := uc.orders.Timeseries(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.TimeseriesResult{
+		Params: params,
+		Points: points,
+	}, nil
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/repository/memory"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestTimeseriesBuckets(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name     string
+		interval string
+		from, to string
+		orders   []string
+		// want lists "bucket start in New York: orders".
+		want []string
+	}{
+		{
+			name:     "days start at local midnight",
+			interval: entity.IntervalDay,
+			from:     "2025-03-10T00:00:00-04:00", to: "2025-03-12T00:00:00-04:00",
+			orders: []string{
+				"2025-03-10T23:59:59-04:00",
+				// Already 11 March in UTC, still 10 March in New York.
+				"2025-03-11T00:30:00Z",
+				"2025-03-11T00:00:00-04:00",
+			},
+			want: []string{"2025-03-10T00:00:00-04:00: 2", "2025-03-11T00:00:00-04:00: 1"},
+		},
+		{
+			name:     "the day clocks go forward has 23 hours",
+			interval: entity.IntervalDay,
+			from:     "2025-03-09T00:00:00-05:00", to: "2025-03-11T00:00:00-04:00",
+			orders: []string{"2025-03-09T00:00:00-05:00", "2025-03-09T23:59:59-04:00", "2025-03-10T00:00:00-04:00"},
+			want:   []string{"2025-03-09T00:00:00-05:00: 2", "2025-03-10T00:00:00-04:00: 1"},
+		},
+		{
+			name:     "empty buckets are omitted and to is exclusive",
+			interval: entity.IntervalDay,
+			from:     "2025-03-10T00:00:00-04:00", to: "2025-03-13T00:00:00-04:00",
+			orders: []string{"2025-03-10T12:00:00-04:00", "2025-03-12T12:00:00-04:00", "2025-03-13T00:00:00-04:00"},
+			want:   []string{"2025-03-10T00:00:00-04:00: 1", "2025-03-12T00:00:00-04:00: 1"},
+		},
+		{
+			name:     "weeks start on Monday",
+			interval: entity.IntervalWeek,
+			from:     "2025-03-01T00:00:00-05:00", to: "2025-03-20T00:00:00-04:00",
+			orders: []string{"2025-03-09T23:59:59-04:00", "2025-03-10T00:00:00-04:00", "2025-03-16T23:00:00-04:00"},
+			want:   []string{"2025-03-03T00:00:00-05:00: 1", "2025-03-10T00:00:00-04:00: 2"},
+		},
+		{
+			name:     "months start on the first",
+			interval: entity.IntervalMonth,
+			from:     "2025-01-01T00:00:00-05:00", to: "2025-04-01T00:00:00-04:00",
+			orders: []string{
+				"2025-02-28T23:59:59-05:00",
+				// 1 March in UTC, 28 February in New York.
+				"2025-03-01T03:00:00Z",
+				"2025-03-01T00:00:00-05:00",
+			},
+			want: []string{"2025-02-01T00:00:00-05:00: 2", "2025-03-01T00:00:00-05:00: 1"},
+		},
+		{
+			name:     "the hour repeated when clocks go back is a bucket of its own",
+			interval: entity.IntervalHour,
+			from:     "2025-11-02T00:00:00-04:00", to: "2025-11-02T03:00:00-05:00",
+			orders: []string{"2025-11-02T01:30:00-04:00", "2025-11-02T01:30:00-05:00", "2025-11-02T01:59:59-05:00"},
+			want:   []string{"2025-11-02T01:00:00-04:00: 1", "2025-11-02T01:00:00-05:00: 2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := memory.NewOrders()
+			batch := make([]*entity.Order, len(tt.orders))
+			for i, timestamp := range tt.orders {
+				batch[i] = entity.NewOrder(40.7, -74, decimal.NewFromInt(10), decimal.Zero, decimal.Zero,
+					decimal.NewFromInt(10), entity.NewTaxBreakdown(decimal.Zero, decimal.Zero, decimal.Zero, decimal.Zero),
+					entity.NewJurisdiction("New York", "New York", "New York City", ""), at(timestamp))
+				batch[i].TenantId = entity.DefaultTenant
+			}
+			if err := orders.CreateBatch(context.Background(), batch); err != nil {
+				t.Fatalf("CreateBatch: %v", err)
+			}
+
+			result, err := NewTimeseriesUseCase(orders, loc).Execute(context.Background(), entity.TimeseriesParams{
+				Interval: tt.interval, From: at(tt.from), To: at(tt.to),
+			})
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			got := make([]string, len(result.Points))
+			for i, point := range result.Points {
+				got[i] = fmt.Sprintf("%s: %d", point.Bucket.In(loc).Format(time.RFC3339), point.Orders)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("points = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeseriesRejectsRanges(t *testing.T) {
+	uc := NewTimeseriesUseCase(memory.NewOrders(), time.UTC)
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		params entity.TimeseriesParams
+		want   error
+	}{
+		{"unknown interval", entity.TimeseriesParams{Interval: "minute"}, ErrInvalidInterval},
+		{"reversed range", entity.TimeseriesParams{From: now, To: now.Add(-time.Hour)}, ErrInvalidTimeRange},
+		{"too many buckets", entity.TimeseriesParams{Interval: entity.IntervalHour,
+			From: now.Add(-(maxTimeseriesBuckets + 1) * time.Hour), To: now}, ErrTooManyBuckets},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := uc.Execute(context.Background(), tt.params); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
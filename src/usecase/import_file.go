@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+var ErrEmptyArchive = errors.New(`archive contains no file`)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// decompressedFile is the content of an uploaded file together with
+// whatever has to be closed once it has been read. An XLSX workbook, itself
+// a zip archive, is kept open as such instead.
+type decompressedFile struct {
+	io.Reader
+	name     string
+	workbook *zip.Reader
+	closers  []io.Closer
+}
+
+func (f *decompressedFile) Close() error {
+	var errs []error
+	for i := len(f.closers) - 1; i >= 0; i-- {
+		errs = append(errs, f.closers[i].Close())
+	}
+	return errors.Join(errs...)
+}
+
+// openImportFile returns the content of an uploaded file, transparently
+// decompressing gzip and zip uploads, together with the name of the file
+// inside. Compression is told from the leading bytes, not the name. A zip
+// upload has to be readable at random; when file cannot be it is spooled to
+// a temporary file first. The caller still closes file.
+func openImportFile(file io.Reader, name string) (*decompressedFile, error) {
+	buffered := bufio.NewReader(file)
+	magic, _ := buffered.Peek(len(zipMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("reading gzip upload: %w", err)
+		}
+		if gz.Name != "" {
+			name = gz.Name
+		} else {
+			name = strings.TrimSuffix(name, path.Ext(name))
+		}
+		return &decompressedFile{Reader: gz, name: name, closers: []io.Closer{gz}}, nil
+	case bytes.HasPrefix(magic, zipMagic):
+		return openZip(file, buffered, name)
+	default:
+		return &decompressedFile{Reader: buffered, name: name}, nil
+	}
+}
+
+func openZip(file io.Reader, buffered io.Reader, name string) (*decompressedFile, error) {
+	var closers []io.Closer
+	fail := func(err error) (*decompressedFile, error) {
+		for _, c := range closers {
+			c.Close()
+		}
+		return nil, err
+	}
+
+	readerAt, size, ok := randomAccess(file)
+	if !ok {
+		spool, err := os.CreateTemp("", "import-*.zip")
+		if err != nil {
+			return fail(err)
+		}
+		os.Remove(spool.Name())
+		closers = append(closers, spool)
+
+		if size, err = io.Copy(spool, buffered); err != nil {
+			return fail(fmt.Errorf("spooling zip upload: %w", err))
+		}
+		readerAt = spool
+	}
+
+	archive, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return fail(fmt.Errorf("reading zip upload: %w", err))
+	}
+	if isWorkbook(archive) {
+		return &decompressedFile{name: name, workbook: archive, closers: closers}, nil
+	}
+
+	for _, entry := range archive.File {
+		if entry.Mode().IsDir() || strings.HasPrefix(entry.Name, "__MACOSX/") ||
+			strings.HasPrefix(path.Base(entry.Name), ".") {
+			continue
+		}
+		content, err := entry.Open()
+		if err != nil {
+			return fail(fmt.Errorf("reading %q in zip upload: %w", entry.Name, err))
+		}
+		closers = append(closers, content)
+		return &decompressedFile{Reader: content, name: path.Base(entry.Name), closers: closers}, nil
+	}
+
+	return fail(ErrEmptyArchive)
+}
+
+// randomAccess reports whether file can be read at arbitrary offsets, as
+// local files and in-memory buffers can, and its size if so.
+func randomAccess(file io.Reader) (io.ReaderAt, int64, bool) {
+	readerAt, ok := file.(io.ReaderAt)
+	if !ok {
+		return nil, 0, false
+	}
+
+	switch f := file.(type) {
+	case interface{ Size() int64 }:
+		return readerAt, f.Size(), true
+	case interface{ Stat() (fs.FileInfo, error) }:
+		info, err := f.Stat()
+		if err != nil {
+			return nil, 0, false
+		}
+		return readerAt, info.Size(), true
+	}
+	return nil, 0, false
+}
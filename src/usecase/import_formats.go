@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+// Import file formats. Every format is read into the positional columns
+// of the CSV format: id, longitude, latitude, timestamp, subtotal.
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New(`unsupported import file format`)
+
+	// errMalformedRecord marks a single unreadable row. The import skips
+	// it and carries on, unlike any other read error.
+	errMalformedRecord = errors.New(`malformed record`)
+)
+
+// jsonFields are the names of the positional columns in JSON and NDJSON
+// records, matching the POST /orders body.
+var jsonFields = [...]string{"id", "longitude", "latitude", "timestamp", "subtotal"}
+
+// recordReader reads an import file one row at a time.
+type recordReader interface {
+	// Read returns the next row's number (its row, line or element number
+	// in the file, increasing) and positional columns. It returns io.EOF
+	// after the last row.
+	Read() (int, []string, error)
+}
+
+// FormatFromContentType maps an upload's declared content type onto an
+// import format. It returns "" for generic or unknown types, leaving the
+// format to be detected from the file itself.
+func FormatFromContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return FormatCSV
+	case "application/json":
+		return FormatJSON
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return FormatNDJSON
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return FormatXLSX
+	default:
+		return ""
+	}
+}
+
+func formatFromName(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".xlsx":
+		return FormatXLSX
+	default:
+		return ""
+	}
+}
+
+// sniffFormat guesses the format of a text file from its first non-blank
+// byte.
+func sniffFormat(r *bufio.Reader) string {
+	head, _ := r.Peek(512)
+	head = bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")), " \t\r\n")
+	switch {
+	case bytes.HasPrefix(head, []byte("[")):
+		return FormatJSON
+	case bytes.HasPrefix(head, []byte("{")):
+		return FormatNDJSON
+	default:
+		return FormatCSV
+	}
+}
+
+// newRecordReader picks the reader for f. An explicit format wins over the
+// file name, which wins over sniffing the content.
+func newRecordReader(f *decompressedFile, format string, location *time.Location) (recordReader, error) {
+	if f.workbook != nil {
+		return newXLSXReader(f.workbook, location)
+	}
+	if format == "" {
+		format = formatFromName(f.name)
+	}
+
+	buffered := bufio.NewReader(f)
+	if format == "" {
+		format = sniffFormat(buffered)
+	}
+
+	switch format {
+	case FormatCSV:
+		return newCSVReader(buffered), nil
+	case FormatJSON:
+		return newJSONReader(buffered)
+	case FormatNDJSON:
+		return newNDJSONReader(buffered), nil
+	default:
+		// Declared as XLSX but not a workbook, or something else entirely.
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	row    int
+}
+
+func newCSVReader(r io.Reader) *csvReader {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	return &csvReader{reader: reader}
+}
+
+func (r *csvReader) Read() (int, []string, error) {
+	// The first row is the header.
+	if r.row == 0 {
+		r.row++
+		if _, err := r.reader.Read(); err != nil && !errors.As(err, new(*csv.ParseError)) {
+			return 0, nil, err
+		}
+	}
+
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return 0, nil, io.EOF
+	}
+	r.row++
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return r.row, nil, fmt.Errorf("%w: %w", errMalformedRecord, err)
+		}
+		return 0, nil, err
+	}
+	return r.row, record, nil
+}
+
+// ndjsonReader reads one JSON object per line; blank lines are skipped but
+// still counted, so row numbers are line numbers.
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	return &ndjsonReader{scanner: scanner}
+}
+
+func (r *ndjsonReader) Read() (int, []string, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		record, err := decodeJSONRecord(line)
+		return r.line, record, err
+	}
+	if err := r.scanner.Err(); err != nil {
+		return 0, nil, err
+	}
+	return 0, nil, io.EOF
+}
+
+// jsonReader streams the objects of a top-level JSON array; row numbers
+// are 1-based element positions. A syntax error in the array itself ends
+// the import, since nothing after it can be located reliably.
+type jsonReader struct {
+	decoder *json.Decoder
+	index   int
+}
+
+func newJSONReader(r io.Reader) (*jsonReader, error) {
+	decoder := json.NewDecoder(r)
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("reading JSON import: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("%w: JSON import must be an array of orders", ErrUnsupportedFormat)
+	}
+	return &jsonReader{decoder: decoder}, nil
+}
+
+func (r *jsonReader) Read() (int, []string, error) {
+	if !r.decoder.More() {
+		return 0, nil, io.EOF
+	}
+	r.index++
+
+	var raw json.RawMessage
+	if err := r.decoder.Decode(&raw); err != nil {
+		return 0, nil, fmt.Errorf("reading JSON import at element %d: %w", r.index, err)
+	}
+	record, err := decodeJSONRecord(raw)
+	return r.index, record, err
+}
+
+func decodeJSONRecord(data []byte) ([]string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %w", errMalformedRecord, err)
+	}
+
+	record := make([]string, len(jsonFields))
+	for i, name := range jsonFields {
+		record[i] = jsonScalar(fields[name])
+	}
+	return record, nil
+}
+
+// jsonScalar renders a JSON string or number as the text a CSV cell would
+// hold; null and missing values become empty.
+func jsonScalar(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
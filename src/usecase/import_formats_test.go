@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/repository/memory"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// importFixtures hold two orders each, the first one with id "first".
+var importFixtures = map[string]string{
+	"orders.csv": "id,longitude,latitude,timestamp,subtotal\n" +
+		"first,-73.9857,40.7484,2025-03-01 09:30:00,12.50\n" +
+		"second,-73.9442,40.6782,2025-03-02 18:45:00,3\n",
+	"orders.json": `[
+		{"id": "first", "longitude": -73.9857, "latitude": 40.7484, "timestamp": "2025-03-01 09:30:00", "subtotal": "12.50"},
+		{"id": "second", "longitude": -73.9442, "latitude": 40.6782, "timestamp": "2025-03-02 18:45:00", "subtotal": 3}
+	]`,
+	"orders.ndjson": `{"id": "first", "longitude": -73.9857, "latitude": 40.7484, "timestamp": "2025-03-01 09:30:00", "subtotal": "12.50"}` + "\n" +
+		`{"id": "second", "longitude": -73.9442, "latitude": 40.6782, "timestamp": "2025-03-02 18:45:00", "subtotal": 3}` + "\n",
+}
+
+func writeFixtures(t *testing.T) map[string]string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := map[string]string{"orders.xlsx": filepath.Join("testdata", "inline_strings.xlsx")}
+	for name, content := range importFixtures {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths[name] = path
+	}
+	return paths
+}
+
+func TestReadersKeepFirstRowOfNewJob(t *testing.T) {
+	job := entity.NewImportJob(entity.DefaultTenant, "orders", "", false)
+	for name, path := range writeFixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			rows := readAll(t, path, "")
+			if len(rows) != 2 {
+				t.Fatalf("read %d rows, want 2", len(rows))
+			}
+			if rows[0].number <= job.CheckpointRow {
+				t.Errorf("first row is numbered %d, at or before the checkpoint %d of a new job",
+					rows[0].number, job.CheckpointRow)
+			}
+		})
+	}
+}
+
+func TestImportKeepsFirstRow(t *testing.T) {
+	for name, path := range writeFixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			orders := memory.NewOrders()
+			job := entity.NewImportJob(entity.DefaultTenant, name, "", false)
+			reporter := newRecordingReporter()
+			if err := newTestImport(inNewYork, orders).Execute(context.Background(), job, f, reporter); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := reporter.lastCheckpoint().RowsImported; got != 2 {
+				t.Errorf("imported %d rows, want 2", got)
+			}
+			result, err := orders.List(context.Background(), entity.ListParams{TenantId: entity.DefaultTenant})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Total != 2 {
+				t.Errorf("stored %d orders, want 2", result.Total)
+			}
+		})
+	}
+}
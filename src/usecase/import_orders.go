@@ -2,83 +2,244 @@ package usecase
 
 import (
 	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/metrics"
 	"context"
-	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"maps"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	importWorkers = 10
+
+	// importChunkSize rows are geocoded, inserted and checkpointed
+	// together. A crash replays at most one chunk.
+	importChunkSize = 500
+)
+
+var (
+	errShortRecord = errors.New(`row has fewer than 5 columns`)
+
+	// errImportDrained is returned by an import stopped for shutdown after
+	// checkpointing the rows it had started.
+	errImportDrained = errors.New(`import drained for shutdown`)
 )
 
 type ImportOrdersUseCase struct {
 	geocodingService GeocodingService
 	orders           Orders
 	taxRates         TaxRates
+	location         *time.Location
+	logger           *slog.Logger
 }
 
 func NewImportOrdersUseCase(geocodingService GeocodingService,
-	orders Orders, taxRates TaxRates) *ImportOrdersUseCase {
+	orders Orders, taxRates TaxRates, location *time.Location, logger *slog.Logger) *ImportOrdersUseCase {
 	return &ImportOrdersUseCase{
 		geocodingService: geocodingService,
 		orders:           orders,
 		taxRates:         taxRates,
+		location:         location,
+		logger:           logger,
 	}
 }
 
-func (uc *ImportOrdersUseCase) Execute(ctx context.Context, fileReader io.Reader) ([]ImportResult, error) {
-	reader := csv.NewReader(fileReader)
+// ImportReporter receives the progress of an import: live after every
+// row, and durably after every chunk.
+type ImportReporter interface {
+	Progress(progress entity.ImportProgress)
+	Checkpoint(progress entity.ImportProgress) error
+	// Draining is closed when the import should wind down: it stops
+	// starting rows, finishes and checkpoints the ones in flight and
+	// returns.
+	Draining() <-chan struct{}
+}
 
-	_, _ = reader.Read()
+// Execute imports the file of job, skipping rows up to its checkpoint. The
+// file may be CSV, JSON, NDJSON or XLSX, optionally compressed.
+// Orders of a chunk are stamped with the job and row, so replaying a chunk
+// that was inserted but not checkpointed does not duplicate them.
+func (uc *ImportOrdersUseCase) Execute(ctx context.Context, job *entity.ImportJob, fileReader io.Reader,
+	reporter ImportReporter) error {
+	// Geocoding for imports is paced by the shared governor and yields to
+	// interactive requests.
+	ctx = withBulkPriority(ctx)
 
-	numWorkers := 10
-	jobs := make(chan ImportJob, 100)
-	results := make(chan ImportResult, 100)
+	progress := job.Progress()
+	if progress.Report == nil {
+		progress.Report = entity.NewImportReport()
+	}
+	geocoder := newMemoGeocoder(uc.geocodingService)
 
-	var wg sync.WaitGroup
+	file, err := openImportFile(fileReader, job.FileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
 
-	for w := 1; w <= numWorkers; w++ {
-		wg.Add(1)
-		go uc.worker(ctx, jobs, results, &wg)
+	records, err := newRecordReader(file, job.Format, uc.location)
+	if err != nil {
+		return err
+	}
+	if closer, ok := records.(io.Closer); ok {
+		defer closer.Close()
 	}
 
-	go func() {
-		rateLimiter := time.NewTicker(time.Second / 20)
-		defer rateLimiter.Stop()
+	chunk := make([]ImportJob, 0, importChunkSize)
+	lastRow := job.CheckpointRow
 
-		rowNum := 2
-		for {
-			record, err := reader.Read()
-			if err == io.EOF {
-				break
+	flush := func() error {
+		// Every chunk is a trace of its own; one spanning the whole job
+		// would stay open for hours.
+		chunkCtx, span := tracer.Start(ctx, "ImportOrdersUseCase.processChunk",
+			trace.WithNewRoot(), trace.WithAttributes(
+				attribute.String("import.id", job.Id.String()),
+				attribute.Int("import.rows", len(chunk))))
+		done, err := uc.processChunk(chunkCtx, geocoder, job, chunk, &progress, reporter)
+		endSpan(span, err)
+		var quotaErr *QuotaExceededError
+		if err != nil && !errors.As(err, &quotaErr) {
+			return err
+		}
+		if done < len(chunk) {
+			// Draining or out of geocoding quota: the rows not done are
+			// read again on resume.
+			progress.RowsRead -= len(chunk) - done
+			if done > 0 {
+				progress.CheckpointRow = chunk[done-1].RowNumber
 			}
-			if err != nil {
-				results <- ImportResult{RowNumber: rowNum, Success: false,
-					Err: fmt.Errorf("csv read error: %w", err)}
-				rowNum++
-				continue
+			if err := reporter.Checkpoint(progress); err != nil {
+				return err
+			}
+			if quotaErr != nil {
+				return quotaErr
+			}
+			return errImportDrained
+		}
+		progress.CheckpointRow = lastRow
+		chunk = chunk[:0]
+		return reporter.Checkpoint(progress)
+	}
+
+	for {
+		rowNum, record, err := records.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !errors.Is(err, errMalformedRecord) {
+			return err
+		}
+		if rowNum <= job.CheckpointRow {
+			continue
+		}
+		progress.RowsRead++
+		lastRow = rowNum
+
+		if err != nil {
+			chunk = append(chunk, ImportJob{RowNumber: rowNum, Err: err})
+		} else {
+			chunk = append(chunk, uc.parseRecord(rowNum, record))
+		}
+
+		if len(chunk) >= importChunkSize {
+			if err := flush(); err != nil {
+				return err
 			}
+		}
+	}
 
-			<-rateLimiter.C
+	return flush()
+}
+
+// parseRecord reads and validates the positional columns id, longitude,
+// latitude, timestamp and subtotal.
+func (uc *ImportOrdersUseCase) parseRecord(rowNum int, record []string) ImportJob {
+	if len(record) < 5 {
+		return ImportJob{RowNumber: rowNum, Err: errShortRecord}
+	}
 
-			lon, _ := strconv.ParseFloat(record[1], 64)
-			lat, _ := strconv.ParseFloat(record[2], 64)
-			timestamp, _ := time.Parse(time.DateTime, record[3])
-			subtotal, _ := decimal.NewFromString(record[4])
+	// The ranges are written so that NaN, which ParseFloat accepts, fails.
+	verr := &ValidationError{}
+	lon, err := strconv.ParseFloat(record[1], 64)
+	if err != nil || !(lon >= -180 && lon <= 180) {
+		verr.Add("longitude", ErrInvalidLongitude)
+	}
+	lat, err := strconv.ParseFloat(record[2], 64)
+	if err != nil || !(lat >= -90 && lat <= 90) {
+		verr.Add("latitude", ErrInvalidLatitude)
+	}
+	timestamp, err := entity.ParseTimestamp(record[3], uc.location)
+	if err != nil {
+		verr.Add("timestamp", fmt.Errorf("%w: %q", ErrFailedParsingTimestamp, record[3]))
+	}
+	subtotal, err := decimal.NewFromString(record[4])
+	if err != nil || subtotal.IsNegative() {
+		verr.Add("subtotal", ErrInvalidSubtotal)
+	}
+	if err := verr.OrNil(); err != nil {
+		return ImportJob{RowNumber: rowNum, Err: err}
+	}
+
+	return ImportJob{
+		RowNumber: rowNum,
+		Latitude:  lat,
+		Longitude: lon,
+		Subtotal:  subtotal,
+		Timestamp: timestamp,
+	}
+}
 
-			jobs <- ImportJob{
-				RowNumber: rowNum,
-				Latitude:  lat,
-				Longitude: lon,
-				Subtotal:  subtotal,
-				Timestamp: timestamp,
+// processChunk geocodes and prices the rows of one chunk concurrently and
+// inserts the resulting orders in a single batch, unless job is a dry run.
+// It returns how many leading rows it processed, which is fewer than all of
+// them only when the reporter started draining or, together with a
+// QuotaExceededError, when the geocoding quota ran out.
+func (uc *ImportOrdersUseCase) processChunk(ctx context.Context, geocoder GeocodingService,
+	job *entity.ImportJob, rows []ImportJob, progress *entity.ImportProgress, reporter ImportReporter) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	jobs := make(chan ImportJob)
+	results := make(chan ImportResult, len(rows))
+
+	var wg sync.WaitGroup
+	for w := 1; w <= importWorkers; w++ {
+		wg.Add(1)
+		go uc.worker(ctx, geocoder, job.TenantId, jobs, results, &wg)
+	}
+
+	// Rows are handed out in order, so the rows started before draining
+	// are a prefix of the chunk and can be checkpointed.
+	started := len(rows)
+	outOfQuota := make(chan struct{})
+	go func() {
+		defer close(jobs)
+		for i, row := range rows {
+			if row.Err != nil {
+				results <- ImportResult{RowNumber: row.RowNumber, Success: false, Err: row.Err}
+				continue
+			}
+			select {
+			case jobs <- row:
+			case <-ctx.Done():
+				return
+			case <-reporter.Draining():
+				started = i
+				return
+			case <-outOfQuota:
+				return
 			}
-			rowNum++
 		}
-		close(jobs)
 	}()
 
 	go func() {
@@ -86,41 +247,118 @@ func (uc *ImportOrdersUseCase) Execute(ctx context.Context, fileReader io.Reader
 		close(results)
 	}()
 
-	const batchSize = 500
+	// Results are taken in row order, so the rows before the first one
+	// that ran out of geocoding quota are a prefix of the chunk too.
+	var quotaErr *QuotaExceededError
+	pending := make(map[int]ImportResult)
+	done := 0
+	toCreate := make([]*entity.Order, 0, len(rows))
+	for res := range results {
+		// Once the job is paused or cancelled, rows still in flight fail
+		// with the context. They are read again on resume, so they are
+		// neither counted nor reported. So are the rows from the first
+		// one out of quota on.
+		if ctx.Err() != nil || quotaErr != nil {
+			continue
+		}
+		pending[res.RowNumber] = res
+		for ; done < len(rows) && quotaErr == nil; done++ {
+			res, ok := pending[rows[done].RowNumber]
+			if !ok {
+				break
+			}
+			delete(pending, res.RowNumber)
+			if errors.As(res.Err, &quotaErr) {
+				close(outOfQuota)
+				break
+			}
+			if res.Success {
+				progress.RowsGeocoded++
+				res.Order.ImportJobId = &job.Id
+				res.Order.ImportRow = res.RowNumber
+				toCreate = append(toCreate, res.Order)
+			} else {
+				countOutcome(progress, importOutcome(res.Err))
+				progress.AddError(res.RowNumber, res.Err)
+				uc.logRowError(ctx, job, res.RowNumber, res.Err)
+			}
+			reporter.Progress(*progress)
+		}
+	}
 
-	var allResults []ImportResult
-	var toCreate []*entity.Order
+	// Rows skipped because of cancellation must not be checkpointed.
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if quotaErr != nil {
+		started = done
+	}
 
-	flush := func() error {
-		if len(toCreate) == 0 {
-			return nil
-		}
-		if err := uc.orders.CreateBatch(ctx, toCreate); err != nil {
-			return fmt.Errorf("batch create failed: %w", err)
+	var rejected map[*entity.Order]error
+	if len(toCreate) > 0 && !job.DryRun {
+		var err error
+		rejected, err = uc.createBatch(ctx, toCreate)
+		if err != nil {
+			return 0, fmt.Errorf("batch create failed: %w", err)
 		}
-		toCreate = toCreate[:0]
-		return nil
+		progress.RowsImported += len(toCreate) - len(rejected)
+		progress.BatchesFlushed++
 	}
 
-	for res := range results {
-		allResults = append(allResults, res)
-		if res.Success {
-			toCreate = append(toCreate, res.Order)
-			if len(toCreate) >= batchSize {
-				if err := flush(); err != nil {
-					return allResults, err
-				}
-			}
-		} else {
-			log.Printf("Import error at row %d: %v", res.RowNumber, res.Err)
+	for _, order := range toCreate {
+		if err, ok := rejected[order]; ok {
+			countOutcome(progress, importOutcome(err))
+			progress.AddError(order.ImportRow, err)
+			uc.logRowError(ctx, job, order.ImportRow, err)
+			continue
 		}
+		countOutcome(progress, outcomeValid)
+		progress.Report.AddOrder(order)
 	}
+	reporter.Progress(*progress)
 
-	if err := flush(); err != nil {
-		return allResults, err
+	if quotaErr != nil {
+		return started, quotaErr
 	}
+	return started, nil
+}
+
+// createBatch inserts orders. When the database rejects the batch because
+// of some row's data, it splits the batch in halves until the offending
+// rows stand alone, inserts the rest and returns the rejected rows.
+func (uc *ImportOrdersUseCase) createBatch(ctx context.Context,
+	orders []*entity.Order) (map[*entity.Order]error, error) {
+	err := uc.orders.CreateBatch(ctx, orders)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, entity.ErrRejected) {
+		return nil, err
+	}
+	if len(orders) == 1 {
+		return map[*entity.Order]error{orders[0]: err}, nil
+	}
+
+	mid := len(orders) / 2
+	rejected, err := uc.createBatch(ctx, orders[:mid])
+	if err != nil {
+		return nil, err
+	}
+	rejectedRight, err := uc.createBatch(ctx, orders[mid:])
+	if err != nil {
+		return nil, err
+	}
+	if rejected == nil {
+		return rejectedRight, nil
+	}
+	maps.Copy(rejected, rejectedRight)
+	return rejected, nil
+}
 
-	return allResults, nil
+// logRowError logs a failed row at debug level: row errors are already
+// counted and sampled on the job, and a bad file can have millions.
+func (uc *ImportOrdersUseCase) logRowError(ctx context.Context, job *entity.ImportJob, row int, err error) {
+	uc.logger.DebugContext(ctx, "Import row failed", "import_id", job.Id, "row", row, "error", err)
 }
 
 type ImportJob struct {
@@ -129,6 +367,7 @@ type ImportJob struct {
 	Longitude float64
 	Subtotal  decimal.Decimal
 	Timestamp time.Time
+	Err       error
 }
 
 type ImportResult struct {
@@ -138,25 +377,16 @@ type ImportResult struct {
 	Order     *entity.Order
 }
 
-func (uc *ImportOrdersUseCase) worker(ctx context.Context,
+func (uc *ImportOrdersUseCase) worker(ctx context.Context, geocoder GeocodingService, tenantId string,
 	jobs <-chan ImportJob, results chan<- ImportResult, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	for job := range jobs {
-		log.Println("Processing row", job.RowNumber)
-		juris, err := uc.geocodingService.GetJurisdiction(job.Latitude, job.Longitude)
-		if err != nil {
-			results <- ImportResult{RowNumber: job.RowNumber, Success: false, Err: err}
-			continue
-		}
-
-		if juris.State != "New York" {
-			results <- ImportResult{RowNumber: job.RowNumber, Success: false,
-				Err: fmt.Errorf("delivery location is outside New York State (got: %s)", juris.State)}
-			continue
-		}
-
-		compositeTaxRate, taxBreakdown, err := uc.taxRates.Get(ctx, juris)
+		rowCtx, span := tracer.Start(ctx, "ImportOrdersUseCase.row",
+			trace.WithAttributes(attribute.Int("import.row", job.RowNumber)))
+		juris, compositeTaxRate, taxBreakdown, err := resolveTax(rowCtx,
+			geocoder, uc.taxRates, tenantId, job.Latitude, job.Longitude)
+		endSpan(span, err)
 		if err != nil {
 			results <- ImportResult{RowNumber: job.RowNumber, Success: false, Err: err}
 			continue
@@ -168,7 +398,89 @@ func (uc *ImportOrdersUseCase) worker(ctx context.Context,
 		order := entity.NewOrder(job.Latitude, job.Longitude,
 			job.Subtotal, compositeTaxRate, taxAmount, totalAmount,
 			taxBreakdown, juris, job.Timestamp)
+		order.TenantId = tenantId
 
 		results <- ImportResult{RowNumber: job.RowNumber, Success: true, Order: order}
 	}
 }
+
+// Row outcomes counted in an import report.
+const (
+	outcomeValid                = "valid"
+	outcomeInvalidRow           = "invalid_row"
+	outcomeOutOfState           = "out_of_state"
+	outcomeJurisdictionNotFound = "jurisdiction_not_found"
+	outcomeGeocodingUnavailable = "geocoding_unavailable"
+	outcomeRejected             = "rejected"
+	outcomeFailed               = "failed"
+)
+
+// countOutcome adds a processed row to the job's report and to the
+// process-wide row metrics.
+func countOutcome(progress *entity.ImportProgress, outcome string) {
+	progress.Report.AddOutcome(outcome)
+	metrics.ImportRows.WithLabelValues(outcome).Inc()
+}
+
+func importOutcome(err error) string {
+	switch {
+	case err == nil:
+		return outcomeValid
+	case errors.Is(err, ErrValidation), errors.Is(err, errShortRecord), errors.Is(err, errMalformedRecord):
+		return outcomeInvalidRow
+	case errors.Is(err, ErrOutOfState):
+		return outcomeOutOfState
+	case errors.Is(err, ErrJurisdictionNotFound):
+		return outcomeJurisdictionNotFound
+	case errors.Is(err, ErrGeocodingUnavailable):
+		return outcomeGeocodingUnavailable
+	case errors.Is(err, entity.ErrRejected):
+		return outcomeRejected
+	default:
+		return outcomeFailed
+	}
+}
+
+// importMemoSize bounds how many delivery points one import remembers.
+const importMemoSize = 50_000
+
+// memoGeocoder remembers the jurisdictions of delivery points already seen
+// in one import, so a point repeated across rows costs one geocoding call.
+type memoGeocoder struct {
+	next GeocodingService
+
+	mu   sync.Mutex
+	seen map[[2]float64]*entity.Jurisdiction
+}
+
+func newMemoGeocoder(next GeocodingService) *memoGeocoder {
+	return &memoGeocoder{
+		next: next,
+		seen: make(map[[2]float64]*entity.Jurisdiction),
+	}
+}
+
+func (m *memoGeocoder) GetJurisdiction(ctx context.Context,
+	latitude, longitude float64) (*entity.Jurisdiction, error) {
+	key := [2]float64{latitude, longitude}
+
+	m.mu.Lock()
+	juris, ok := m.seen[key]
+	m.mu.Unlock()
+	if ok {
+		return juris, nil
+	}
+
+	juris, err := m.next.GetJurisdiction(ctx, latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	if len(m.seen) < importMemoSize {
+		m.seen[key] = juris
+	}
+	m.mu.Unlock()
+
+	return juris, nil
+}
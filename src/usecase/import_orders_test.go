@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/repository/memory"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// recordingReporter keeps every progress report and checkpoint of an
+// import. Closing draining asks the import to wind down.
+type recordingReporter struct {
+	draining chan struct{}
+
+	mu          sync.Mutex
+	progress    []entity.ImportProgress
+	checkpoints []entity.ImportProgress
+}
+
+func newRecordingReporter() *recordingReporter {
+	return &recordingReporter{draining: make(chan struct{})}
+}
+
+func (r *recordingReporter) Progress(progress entity.ImportProgress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress = append(r.progress, progress)
+}
+
+func (r *recordingReporter) Checkpoint(progress entity.ImportProgress) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkpoints = append(r.checkpoints, progress)
+	return nil
+}
+
+func (r *recordingReporter) Draining() <-chan struct{} {
+	return r.draining
+}
+
+func (r *recordingReporter) lastCheckpoint() entity.ImportProgress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.checkpoints[len(r.checkpoints)-1]
+}
+
+// ordersCSV renders n valid rows, with ids 1 to n, at distinct points.
+func ordersCSV(n int) string {
+	var b strings.Builder
+	b.WriteString("id,longitude,latitude,timestamp,subtotal\n")
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "%d,-74.%04d,40.7128,2025-01-01 10:00:00,10.00\n", i, i)
+	}
+	return b.String()
+}
+
+func newTestImport(geocoder GeocodingService, orders Orders) *ImportOrdersUseCase {
+	return NewImportOrdersUseCase(geocoder, orders, flatRate{}, time.UTC, slog.New(slog.DiscardHandler))
+}
+
+func TestImportCancelDoesNotCountRowsInFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Geocoding hangs until the job is cancelled, then fails the way an
+	// HTTP client does.
+	entered := make(chan struct{}, importWorkers)
+	hanging := geocoderFunc(func(ctx context.Context, _, _ float64) (*entity.Jurisdiction, error) {
+		entered <- struct{}{}
+		<-ctx.Done()
+		return nil, fmt.Errorf("geocoding request: %w", ctx.Err())
+	})
+
+	reporter := newRecordingReporter()
+	job := entity.NewImportJob(entity.DefaultTenant, "orders.csv", "", false)
+	done := make(chan error, 1)
+	go func() {
+		done <- newTestImport(hanging, memory.NewOrders()).Execute(ctx, job, strings.NewReader(ordersCSV(50)), reporter)
+	}()
+
+	for range importWorkers {
+		<-entered
+	}
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	for _, p := range reporter.progress {
+		if p.RowsFailed > 0 || len(p.ErrorSamples) > 0 {
+			t.Fatalf("progress reported %d failed rows, samples %v; want none", p.RowsFailed, p.ErrorSamples)
+		}
+	}
+	if len(reporter.checkpoints) > 0 {
+		t.Errorf("checkpointed %+v after cancellation", reporter.checkpoints)
+	}
+}
+
+// collidingOrders gives the order of one import row the id of an order
+// already stored, which the memory backend rejects with
+// entity.ErrRejected, and counts the batches it is asked to insert.
+type collidingOrders struct {
+	*memory.Orders
+	row     int
+	id      uuid.UUID
+	batches int
+}
+
+func (o *collidingOrders) CreateBatch(ctx context.Context, orders []*entity.Order) error {
+	o.batches++
+	for _, order := range orders {
+		if order.ImportRow == o.row {
+			order.Id = o.id
+		}
+	}
+	return o.Orders.CreateBatch(ctx, orders)
+}
+
+func TestImportIsolatesRejectedRows(t *testing.T) {
+	const rows, badRow = importChunkSize, 300
+	ctx := context.Background()
+
+	existing := entity.NewOrder(40.7, -74, decimal.NewFromInt(1), decimal.Zero, decimal.Zero, decimal.NewFromInt(1),
+		entity.NewTaxBreakdown(decimal.Zero, decimal.Zero, decimal.Zero, decimal.Zero),
+		entity.NewJurisdiction("New York", "New York County", "New York", ""), time.Now())
+	existing.TenantId = entity.DefaultTenant
+	orders := &collidingOrders{Orders: memory.NewOrders(), row: badRow, id: existing.Id}
+	if err := orders.Orders.CreateBatch(ctx, []*entity.Order{existing}); err != nil {
+		t.Fatal(err)
+	}
+
+	reporter := newRecordingReporter()
+	job := entity.NewImportJob(entity.DefaultTenant, "orders.csv", "", false)
+	if err := newTestImport(inNewYork, orders).Execute(ctx, job, strings.NewReader(ordersCSV(rows)), reporter); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	// The chunk was split down to the bad row rather than failed whole.
+	if orders.batches < 2 {
+		t.Errorf("%d batches inserted, want the chunk split", orders.batches)
+	}
+	final := reporter.lastCheckpoint()
+	if final.RowsImported != rows-1 || final.RowsFailed != 1 || final.Report.Outcomes[outcomeRejected] != 1 {
+		t.Errorf("final progress = %+v with outcomes %v, want only row %d rejected",
+			final, final.Report.Outcomes, badRow)
+	}
+	if len(final.ErrorSamples) != 1 || final.ErrorSamples[0].Row != badRow ||
+		!strings.Contains(final.ErrorSamples[0].Message, "duplicate order id") {
+		t.Errorf("error samples = %+v, want the duplicate id of row %d", final.ErrorSamples, badRow)
+	}
+
+	imported := make(map[int]bool)
+	err := orders.Stream(ctx, entity.ListParams{TenantId: entity.DefaultTenant}, func(order *entity.Order) error {
+		if order.ImportJobId != nil {
+			imported[order.ImportRow] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	for row := 2; row <= rows+1; row++ {
+		if imported[row] == (row == badRow) {
+			t.Errorf("row %d imported = %v, want only row %d left out", row, imported[row], badRow)
+		}
+	}
+}
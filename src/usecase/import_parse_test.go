@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRecord(t *testing.T) {
+	uc := newTestImport(inNewYork, nil)
+	tests := []struct {
+		name   string
+		record []string
+		want   []error
+	}{
+		{"valid", []string{"1", "-73.9857", "40.7484", "2025-03-01 09:30:00", "12.50"}, nil},
+		{"short", []string{"1", "-73.9857", "40.7484"}, []error{errShortRecord}},
+		{"nan", []string{"1", "NaN", "nan", "2025-03-01 09:30:00", "1"},
+			[]error{ErrInvalidLongitude, ErrInvalidLatitude}},
+		{"infinite", []string{"1", "-Inf", "+Inf", "2025-03-01 09:30:00", "1"},
+			[]error{ErrInvalidLongitude, ErrInvalidLatitude}},
+		{"out of range", []string{"1", "-181", "91", "2025-03-01 09:30:00", "1"},
+			[]error{ErrInvalidLongitude, ErrInvalidLatitude}},
+		{"bad values", []string{"1", "-73.9857", "40.7484", "yesterday", "-1"},
+			[]error{ErrFailedParsingTimestamp, ErrInvalidSubtotal}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := uc.parseRecord(7, tt.record)
+			if row.RowNumber != 7 {
+				t.Errorf("row = %d, want 7", row.RowNumber)
+			}
+			if tt.want == nil {
+				if row.Err != nil {
+					t.Fatalf("err = %v, want none", row.Err)
+				}
+				if !row.Timestamp.Equal(time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)) || row.Subtotal.String() != "12.5" {
+					t.Errorf("parsed %+v", row)
+				}
+				return
+			}
+			for _, want := range tt.want {
+				if !errors.Is(row.Err, want) {
+					t.Errorf("err = %v, want it to match %v", row.Err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportProgressHub holds the live state of the imports running in this
+// process and wakes up everyone watching them. Updates are coalesced: a
+// slow watcher skips intermediate states and reads the latest one.
+type ImportProgressHub struct {
+	mu   sync.Mutex
+	live map[uuid.UUID]*liveImport
+}
+
+type liveImport struct {
+	job      *entity.ImportJob
+	watchers map[chan struct{}]struct{}
+	done     chan struct{}
+}
+
+func NewImportProgressHub() *ImportProgressHub {
+	return &ImportProgressHub{
+		live: make(map[uuid.UUID]*liveImport),
+	}
+}
+
+// start begins publishing job, which has just been claimed.
+func (h *ImportProgressHub) start(job *entity.ImportJob) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.live[job.Id] = &liveImport{
+		job:      job,
+		watchers: make(map[chan struct{}]struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (h *ImportProgressHub) publish(id uuid.UUID, progress entity.ImportProgress) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if l, ok := h.live[id]; ok {
+		l.job = l.job.WithProgress(progress)
+		l.job.UpdatedAt = time.Now()
+		l.notify()
+	}
+}
+
+// stop publishes the final state of a job that stopped running here and
+// releases its watchers.
+func (h *ImportProgressHub) stop(job *entity.ImportJob) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	l, ok := h.live[job.Id]
+	if !ok {
+		return
+	}
+	delete(h.live, job.Id)
+
+	l.job = job
+	l.notify()
+	close(l.done)
+}
+
+// watch subscribes to a job running in this process. It returns false when
+// the job is not running here.
+func (h *ImportProgressHub) watch(id uuid.UUID) (*importWatch, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	l, ok := h.live[id]
+	if !ok {
+		return nil, false
+	}
+
+	w := &importWatch{hub: h, live: l, changed: make(chan struct{}, 1)}
+	l.watchers[w.changed] = struct{}{}
+	w.changed <- struct{}{}
+
+	return w, true
+}
+
+func (l *liveImport) notify() {
+	for ch := range l.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+type importWatch struct {
+	hub     *ImportProgressHub
+	live    *liveImport
+	changed chan struct{}
+}
+
+func (w *importWatch) current() *entity.ImportJob {
+	w.hub.mu.Lock()
+	defer w.hub.mu.Unlock()
+	return w.live.job
+}
+
+func (w *importWatch) close() {
+	w.hub.mu.Lock()
+	defer w.hub.mu.Unlock()
+	delete(w.live.watchers, w.changed)
+}
@@ -0,0 +1,275 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"InstantWellnessKits/src/repository/memory"
+	"InstantWellnessKits/src/repository/storage"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var errCheckpointLost = errors.New(`checkpoint lost`)
+
+// failingReporter records like recordingReporter but fails checkpoint
+// number failAt, as when the lease is lost or the database goes away
+// after a chunk was inserted.
+type failingReporter struct {
+	*recordingReporter
+	failAt int
+	calls  int
+}
+
+func (r *failingReporter) Checkpoint(progress entity.ImportProgress) error {
+	r.calls++
+	if r.calls == r.failAt {
+		return errCheckpointLost
+	}
+	return r.recordingReporter.Checkpoint(progress)
+}
+
+// assertImportedOnce checks that orders holds exactly one order for every
+// row of ordersCSV(n), whose header is row 1.
+func assertImportedOnce(t *testing.T, orders *memory.Orders, n int) {
+	t.Helper()
+	var rows []int
+	err := orders.Stream(context.Background(), entity.ListParams{TenantId: entity.DefaultTenant},
+		func(order *entity.Order) error {
+			rows = append(rows, order.ImportRow)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	slices.Sort(rows)
+	if len(rows) != n || len(slices.Compact(slices.Clone(rows))) != n || rows[0] != 2 || rows[n-1] != n+1 {
+		t.Fatalf("imported %d orders over rows %v..%v, want rows 2 to %d once each",
+			len(rows), rows[:min(len(rows), 1)], rows[max(len(rows)-1, 0):], n+1)
+	}
+}
+
+func TestImportResumesAfterDrain(t *testing.T) {
+	const rows = 1200
+	ctx := context.Background()
+	orders := memory.NewOrders()
+	job := entity.NewImportJob(entity.DefaultTenant, "orders.csv", "", false)
+
+	// Shutdown starts while the second chunk is being geocoded.
+	reporter := newRecordingReporter()
+	var geocoded atomic.Int64
+	var drain sync.Once
+	geocoder := geocoderFunc(func(ctx context.Context, latitude, longitude float64) (*entity.Jurisdiction, error) {
+		if geocoded.Add(1) == 600 {
+			drain.Do(func() { close(reporter.draining) })
+		}
+		return inNewYork(ctx, latitude, longitude)
+	})
+
+	err := newTestImport(geocoder, orders).Execute(ctx, job, strings.NewReader(ordersCSV(rows)), reporter)
+	if !errors.Is(err, errImportDrained) {
+		t.Fatalf("err = %v, want %v", err, errImportDrained)
+	}
+	checkpoint := reporter.lastCheckpoint()
+	// Rows are numbered from the header, so row n+1 holds the nth order.
+	imported := checkpoint.CheckpointRow - 1
+	if imported < 600 || imported > 2*importChunkSize {
+		t.Fatalf("drained at row %d, want within the second chunk or at its end", checkpoint.CheckpointRow)
+	}
+	if checkpoint.RowsRead != imported || checkpoint.RowsImported != imported {
+		t.Errorf("checkpoint = %+v, want every row up to the checkpoint read and imported", checkpoint)
+	}
+
+	resumed := newRecordingReporter()
+	err = newTestImport(inNewYork, orders).Execute(ctx, job.WithProgress(checkpoint),
+		strings.NewReader(ordersCSV(rows)), resumed)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+
+	final := resumed.lastCheckpoint()
+	if final.CheckpointRow != rows+1 || final.RowsRead != rows || final.RowsImported != rows || final.RowsFailed != 0 {
+		t.Errorf("final progress = %+v, want all %d rows read and imported", final, rows)
+	}
+	assertImportedOnce(t, orders, rows)
+}
+
+func TestImportReplaysChunkAfterLostCheckpoint(t *testing.T) {
+	const rows = 1200
+	ctx := context.Background()
+	orders := memory.NewOrders()
+	job := entity.NewImportJob(entity.DefaultTenant, "orders.csv", "", false)
+
+	// The second chunk is inserted, but its checkpoint never lands.
+	reporter := &failingReporter{recordingReporter: newRecordingReporter(), failAt: 2}
+	err := newTestImport(inNewYork, orders).Execute(ctx, job, strings.NewReader(ordersCSV(rows)), reporter)
+	if !errors.Is(err, errCheckpointLost) {
+		t.Fatalf("err = %v, want %v", err, errCheckpointLost)
+	}
+	checkpoint := reporter.lastCheckpoint()
+	if checkpoint.CheckpointRow != importChunkSize+1 {
+		t.Fatalf("checkpoint at row %d, want %d", checkpoint.CheckpointRow, importChunkSize+1)
+	}
+
+	resumed := newRecordingReporter()
+	err = newTestImport(inNewYork, orders).Execute(ctx, job.WithProgress(checkpoint),
+		strings.NewReader(ordersCSV(rows)), resumed)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+
+	// The replayed chunk is not inserted twice.
+	if final := resumed.lastCheckpoint(); final.CheckpointRow != rows+1 || final.RowsRead != rows || final.RowsFailed != 0 {
+		t.Errorf("final progress = %+v, want all %d rows read and none failed", final, rows)
+	}
+	assertImportedOnce(t, orders, rows)
+}
+
+type testQueue struct {
+	jobs   *memory.ImportJobs
+	files  *storage.Local
+	orders *memory.Orders
+}
+
+func newTestQueue(t *testing.T) *testQueue {
+	t.Helper()
+	files, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testQueue{jobs: memory.NewImportJobs(), files: files, orders: memory.NewOrders()}
+}
+
+func (q *testQueue) enqueue(t *testing.T, rows int) *entity.ImportJob {
+	t.Helper()
+	job, err := NewEnqueueImportUseCase(q.files, q.jobs, slog.New(slog.DiscardHandler)).
+		Execute(context.Background(), "orders.csv", "text/csv", strings.NewReader(ordersCSV(rows)), false)
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	return job
+}
+
+func (q *testQueue) runner(geocoder GeocodingService) *ImportRunner {
+	return NewImportRunner(q.jobs, q.files, newTestImport(geocoder, q.orders), NewImportProgressHub(),
+		10*time.Millisecond, time.Minute, slog.New(slog.DiscardHandler))
+}
+
+// waitForStatus polls job id until it reaches status.
+func (q *testQueue) waitForStatus(t *testing.T, id uuid.UUID, status entity.ImportStatus) *entity.ImportJob {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		job, err := q.jobs.Get(context.Background(), entity.DefaultTenant, id)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job is %s with progress %+v, want %s", job.Status, job.Progress(), status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestImportRunnerRequeuesOnShutdown(t *testing.T) {
+	const rows = 1200
+	q := newTestQueue(t)
+	job := q.enqueue(t, rows)
+
+	// The first replica shuts down while the second chunk is geocoded.
+	ctx, shutdown := context.WithCancel(context.Background())
+	var geocoded atomic.Int64
+	geocoder := geocoderFunc(func(gctx context.Context, latitude, longitude float64) (*entity.Jurisdiction, error) {
+		if geocoded.Add(1) == 600 {
+			shutdown()
+		}
+		return inNewYork(gctx, latitude, longitude)
+	})
+	q.runner(geocoder).Run(ctx, 1, 10*time.Second)
+
+	requeued := q.waitForStatus(t, job.Id, entity.ImportQueued)
+	if requeued.CheckpointRow <= importChunkSize+1 || requeued.CheckpointRow > rows {
+		t.Fatalf("requeued at row %d, want past the first chunk", requeued.CheckpointRow)
+	}
+
+	// Another replica picks the job up right away and finishes it.
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.runner(inNewYork).Run(ctx, 1, time.Second)
+	}()
+	completed := q.waitForStatus(t, job.Id, entity.ImportCompleted)
+	stop()
+	<-done
+
+	if completed.RowsRead != rows || completed.RowsImported != rows || completed.RowsFailed != 0 {
+		t.Errorf("completed job = %+v, want all %d rows imported", completed.Progress(), rows)
+	}
+	assertImportedOnce(t, q.orders, rows)
+	if _, err := q.files.Open(context.Background(), job.FileKey); err == nil {
+		t.Error("upload was kept after the import completed")
+	}
+}
+
+func TestImportRunnerPostponesWhenQuotaRunsOut(t *testing.T) {
+	const rows, dailyCap = 1200, 700
+	q := newTestQueue(t)
+	job := q.enqueue(t, rows)
+
+	governor := NewGeocodingGovernor(inNewYork, 1e6, dailyCap, time.UTC, slog.New(slog.DiscardHandler))
+	defer governor.Close()
+	runControlled(t, q, governor)
+
+	deadline := time.Now().Add(10 * time.Second)
+	postponed, err := q.jobs.Get(context.Background(), entity.DefaultTenant, job.Id)
+	for err == nil && postponed.ResumeAt == nil && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		postponed, err = q.jobs.Get(context.Background(), entity.DefaultTenant, job.Id)
+	}
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if postponed.ResumeAt == nil {
+		t.Fatalf("job is %s with progress %+v, want it postponed", postponed.Status, postponed.Progress())
+	}
+
+	// The rows before the first one out of quota are checkpointed; the
+	// rest wait in the queue, not in a worker, for the quota to reset.
+	// Workers take slots out of row order, so the last few slots may have
+	// gone to rows after it.
+	imported := postponed.CheckpointRow - 1
+	if imported <= dailyCap-importWorkers || imported > dailyCap {
+		t.Fatalf("postponed at row %d, want close to row %d", postponed.CheckpointRow, dailyCap+1)
+	}
+	if postponed.Status != entity.ImportQueued || postponed.RowsRead != imported ||
+		postponed.RowsImported != imported || postponed.RowsFailed != 0 {
+		t.Errorf("postponed job is %s with progress %+v, want queued with every row up to the checkpoint imported",
+			postponed.Status, postponed.Progress())
+	}
+	if !strings.Contains(postponed.Error, "quota") {
+		t.Errorf("error = %q, want it to say the quota ran out", postponed.Error)
+	}
+	if until := time.Until(*postponed.ResumeAt); until <= 0 || until > 24*time.Hour ||
+		!postponed.ResumeAt.Equal(postponed.ResumeAt.UTC().Truncate(24*time.Hour)) {
+		t.Errorf("resumes at %s, want the next midnight in the quota timezone", postponed.ResumeAt)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if current, _ := q.jobs.Get(context.Background(), entity.DefaultTenant, job.Id); current.Status != entity.ImportQueued {
+		t.Errorf("postponed job was claimed again and is %s", current.Status)
+	}
+	if n := importedCount(t, q); n != imported {
+		t.Errorf("%d orders imported, want %d", n, imported)
+	}
+}
@@ -0,0 +1,349 @@
+package usecase
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Excel stores dates as days since this epoch, counting the non-existent
+// 29 February 1900, which the epoch absorbs for every date after it.
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// builtinDateFormats are the number format ids Excel reserves for dates
+// and times.
+var builtinDateFormats = map[int]bool{
+	14: true, 15: true, 16: true, 17: true, 18: true, 19: true, 20: true, 21: true, 22: true,
+	45: true, 46: true, 47: true,
+}
+
+var (
+	quotedText      = regexp.MustCompile(`"[^"]*"|\[[^\]]*\]|\\.`)
+	dateFormatRunes = regexp.MustCompile(`[dDmMyYhHsS]`)
+)
+
+// xlsxReader streams the rows of the first worksheet of a workbook. The
+// first non-empty row is the header; row numbers are the sheet's own.
+// Cells formatted as dates are turned back into timestamps.
+type xlsxReader struct {
+	decoder  *xml.Decoder
+	sheet    io.Closer
+	strings  []string
+	dateXfs  map[int]bool
+	location *time.Location
+
+	lastRow    int
+	seenHeader bool
+}
+
+func isWorkbook(archive *zip.Reader) bool {
+	for _, f := range archive.File {
+		if f.Name == "xl/workbook.xml" {
+			return true
+		}
+	}
+	return false
+}
+
+func newXLSXReader(archive *zip.Reader, location *time.Location) (*xlsxReader, error) {
+	sheetPath, err := firstSheetPath(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := readSharedStrings(archive)
+	if err != nil {
+		return nil, err
+	}
+	dateXfs, err := readDateStyles(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, err := archive.Open(sheetPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening worksheet %q: %w", sheetPath, err)
+	}
+
+	return &xlsxReader{
+		decoder:  xml.NewDecoder(sheet),
+		sheet:    sheet,
+		strings:  shared,
+		dateXfs:  dateXfs,
+		location: location,
+	}, nil
+}
+
+func (r *xlsxReader) Close() error {
+	return r.sheet.Close()
+}
+
+func (r *xlsxReader) Read() (int, []string, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return 0, nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row xlsxRow
+		if err := r.decoder.DecodeElement(&row, &start); err != nil {
+			return 0, nil, fmt.Errorf("reading worksheet row: %w", err)
+		}
+
+		num := r.lastRow + 1
+		if row.Number > 0 {
+			num = row.Number
+		}
+		r.lastRow = num
+
+		record, err := r.record(row)
+		if err != nil {
+			return num, nil, fmt.Errorf("%w: %w", errMalformedRecord, err)
+		}
+		if isBlank(record) {
+			continue
+		}
+		if !r.seenHeader {
+			r.seenHeader = true
+			continue
+		}
+		return num, record, nil
+	}
+}
+
+func (r *xlsxReader) record(row xlsxRow) ([]string, error) {
+	record := make([]string, 0, len(row.Cells))
+	for _, c := range row.Cells {
+		column := len(record)
+		if c.Ref != "" {
+			column = columnIndex(c.Ref)
+		}
+		for len(record) < column {
+			record = append(record, "")
+		}
+
+		value, err := r.cellValue(c)
+		if err != nil {
+			return nil, fmt.Errorf("cell %s: %w", c.Ref, err)
+		}
+		record = append(record, value)
+	}
+	return record, nil
+}
+
+func (r *xlsxReader) cellValue(c xlsxCell) (string, error) {
+	switch c.Type {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(c.Value))
+		if err != nil || i < 0 || i >= len(r.strings) {
+			return "", fmt.Errorf("invalid shared string %q", c.Value)
+		}
+		return r.strings[i], nil
+	case "inlineStr":
+		return c.Inline.text(), nil
+	case "str", "b", "e":
+		return c.Value, nil
+	}
+
+	if c.Style != "" && c.Value != "" {
+		style, err := strconv.Atoi(c.Style)
+		if err == nil && r.dateXfs[style] {
+			serial, err := strconv.ParseFloat(c.Value, 64)
+			if err != nil {
+				return "", fmt.Errorf("invalid date %q", c.Value)
+			}
+			return excelTime(serial).Format("2006-01-02 15:04:05.999999999"), nil
+		}
+	}
+	return c.Value, nil
+}
+
+// excelTime converts a date serial to the wall clock time it shows; the
+// importer reads it in the business timezone like a naive CSV timestamp.
+func excelTime(serial float64) time.Time {
+	ms := math.Round(serial * 24 * 60 * 60 * 1000)
+	return excelEpoch.Add(time.Duration(ms) * time.Millisecond)
+}
+
+// columnIndex turns the letters of a cell reference such as "AB12" into a
+// 0-based column number.
+func columnIndex(ref string) int {
+	column := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		column = column*26 + int(ch-'A'+1)
+	}
+	return column - 1
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+type xlsxRow struct {
+	Number int        `xml:"r,attr"`
+	Cells  []xlsxCell `xml:"c"`
+}
+
+type xlsxCell struct {
+	Ref    string     `xml:"r,attr"`
+	Type   string     `xml:"t,attr"`
+	Style  string     `xml:"s,attr"`
+	Value  string     `xml:"v"`
+	Inline xlsxString `xml:"is"`
+}
+
+// xlsxString is plain (<t>) or rich (<r><t>) text.
+type xlsxString struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (s xlsxString) text() string {
+	if len(s.Runs) == 0 {
+		return s.Text
+	}
+	var b strings.Builder
+	b.WriteString(s.Text)
+	for _, run := range s.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+func firstSheetPath(archive *zip.Reader) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			RelId string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeXMLPart(archive, "xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("%w: workbook has no worksheets", ErrEmptyArchive)
+	}
+
+	var rels struct {
+		Relationships []struct {
+			Id     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeXMLPart(archive, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.Id != workbook.Sheets[0].RelId {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", fmt.Errorf("worksheet %q not found in workbook", workbook.Sheets[0].RelId)
+}
+
+func readSharedStrings(archive *zip.Reader) ([]string, error) {
+	var table struct {
+		Items []xlsxString `xml:"si"`
+	}
+	err := decodeXMLPart(archive, "xl/sharedStrings.xml", &table)
+	if err != nil && !isMissingPart(err) {
+		return nil, err
+	}
+
+	shared := make([]string, len(table.Items))
+	for i, item := range table.Items {
+		shared[i] = item.text()
+	}
+	return shared, nil
+}
+
+// readDateStyles returns the cell style indexes whose number format shows
+// a date or time.
+func readDateStyles(archive *zip.Reader) (map[int]bool, error) {
+	var styles struct {
+		NumFmts []struct {
+			Id   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtId int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	err := decodeXMLPart(archive, "xl/styles.xml", &styles)
+	if err != nil && !isMissingPart(err) {
+		return nil, err
+	}
+
+	dateFormats := make(map[int]bool, len(builtinDateFormats))
+	for id := range builtinDateFormats {
+		dateFormats[id] = true
+	}
+	for _, f := range styles.NumFmts {
+		if dateFormatRunes.MatchString(quotedText.ReplaceAllString(f.Code, "")) {
+			dateFormats[f.Id] = true
+		}
+	}
+
+	dateXfs := make(map[int]bool)
+	for i, xf := range styles.CellXfs {
+		if dateFormats[xf.NumFmtId] {
+			dateXfs[i] = true
+		}
+	}
+	return dateXfs, nil
+}
+
+type missingPartError string
+
+func (e missingPartError) Error() string {
+	return fmt.Sprintf("workbook part %q not found", string(e))
+}
+
+func isMissingPart(err error) bool {
+	_, ok := err.(missingPartError)
+	return ok
+}
+
+func decodeXMLPart(archive *zip.Reader, name string, v any) error {
+	for _, f := range archive.File {
+		if f.Name != name {
+			continue
+		}
+		part, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("opening %q: %w", name, err)
+		}
+		defer part.Close()
+
+		if err := xml.NewDecoder(part).Decode(v); err != nil {
+			return fmt.Errorf("reading %q: %w", name, err)
+		}
+		return nil
+	}
+	return missingPartError(name)
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type readRow struct {
+	number int
+	record []string
+}
+
+// readAll reads every row of the import file at path.
+func readAll(t *testing.T, path, format string) []readRow {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	file, err := openImportFile(f, filepath.Base(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records, err := newRecordReader(file, format, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closer, ok := records.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	var rows []readRow
+	for {
+		number, record, err := records.Read()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		if err != nil {
+			t.Fatalf("row %d: %v", number, err)
+		}
+		rows = append(rows, readRow{number, append([]string(nil), record...)})
+	}
+}
+
+// The fixtures are workbooks written by excelize, as a spreadsheet
+// application would save them.
+func TestXLSXReader(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []readRow
+	}{
+		{
+			// Text in the shared strings table; timestamps as date cells in
+			// a custom format, the built-in formats 22 and 14, and as text.
+			fixture: "shared_strings.xlsx",
+			want: []readRow{
+				{2, []string{"ord-1", "-73.9857", "40.7484", "2025-03-01 09:30:00", "12.5"}},
+				{3, []string{"ord-2", "-73.9442", "40.6782", "2025-03-02 18:45:00", "100"}},
+				{4, []string{"ord-3", "-78.8784", "42.8864", "2025-03-03 00:00:00", "0.99"}},
+				{5, []string{"ord-1", "-73.9857", "40.7484", "2025-03-04 08:00:00", "7"}},
+			},
+		},
+		{
+			// Header on row 3, a missing and a whitespace-only row, skipped
+			// columns and a cell past the last column.
+			fixture: "sparse.xlsx",
+			want: []readRow{
+				{4, []string{"1", "-73.9857", "40.7484", "2025-03-01T09:30:00Z", "12.5"}},
+				{7, []string{"2", "-73.9442", "", "", "5"}},
+				{9, []string{"3", "-73.95", "40.65", "2025-03-05 12:00", "20", "", "", "fragile"}},
+			},
+		},
+		{
+			// Inline strings, as written by streaming exporters.
+			fixture: "inline_strings.xlsx",
+			want: []readRow{
+				{2, []string{"s-1", "-73.9857", "40.7484", "2025-03-01 09:30:00", "12.5"}},
+				{3, []string{"s-2", "-73.9442", "40.6782", "2025-03-02 18:45:00", "3"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got := readAll(t, filepath.Join("testdata", tt.fixture), "")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %v\nwant   %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "E12": 4, "Z3": 25, "AA3": 26, "AB100": 27, "XFD1": 16383} {
+		if got := columnIndex(ref); got != want {
+			t.Errorf("columnIndex(%q) = %d, want %d", ref, got, want)
+		}
+	}
+}
+
+func TestExcelTime(t *testing.T) {
+	tests := map[float64]time.Time{
+		1:                  time.Date(1899, time.December, 31, 0, 0, 0, 0, time.UTC),
+		61:                 time.Date(1900, time.March, 1, 0, 0, 0, 0, time.UTC),
+		45717.395833333336: time.Date(2025, time.March, 1, 9, 30, 0, 0, time.UTC),
+		45718.78125:        time.Date(2025, time.March, 2, 18, 45, 0, 0, time.UTC),
+	}
+	for serial, want := range tests {
+		if got := excelTime(serial); !got.Equal(want) {
+			t.Errorf("excelTime(%v) = %s, want %s", serial, got, want)
+		}
+	}
+}
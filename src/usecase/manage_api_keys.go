@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPrefix      = "iwk_"
+	apiKeyRandomBytes = 32
+	apiKeyShownChars  = 8
+)
+
+var (
+	ErrInvalidRole  = errors.New(`unknown role`)
+	ErrMissingRoles = errors.New(`at least one role is required`)
+)
+
+type ManageAPIKeysUseCase struct {
+	apiKeys APIKeys
+}
+
+func NewManageAPIKeysUseCase(apiKeys APIKeys) *ManageAPIKeysUseCase {
+	return &ManageAPIKeysUseCase{
+		apiKeys: apiKeys,
+	}
+}
+
+// Issue creates a key and returns its plaintext. The plaintext is never
+// stored and cannot be recovered afterwards.
+func (uc *ManageAPIKeysUseCase) Issue(ctx context.Context, name, tenantId string,
+	roleNames []string) (string, *entity.APIKey, error) {
+	verr := &ValidationError{}
+	if name == "" {
+		verr.Add("name", ErrMissingField)
+	}
+	if tenantId == "" {
+		verr.Add("tenant", ErrMissingField)
+	}
+	if len(roleNames) == 0 {
+		verr.Add("roles", ErrMissingRoles)
+	}
+	roles := make([]entity.Role, 0, len(roleNames))
+	for _, roleName := range roleNames {
+		role, ok := entity.ParseRole(roleName)
+		if !ok {
+			verr.Add("roles", ErrInvalidRole)
+			continue
+		}
+		roles = append(roles, role)
+	}
+	if err := verr.OrNil(); err != nil {
+		return "", nil, err
+	}
+
+	secret := make([]byte, apiKeyRandomBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	plaintext := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := entity.NewAPIKey(name, plaintext[:len(apiKeyPrefix)+apiKeyShownChars], tenantId, roles)
+	if err := uc.apiKeys.Create(ctx, key, HashAPIKey(plaintext)); err != nil {
+		return "", nil, err
+	}
+
+	return plaintext, key, nil
+}
+
+func (uc *ManageAPIKeysUseCase) Revoke(ctx context.Context, id uuid.UUID) error {
+	return uc.apiKeys.Revoke(ctx, id)
+}
+
+func (uc *ManageAPIKeysUseCase) List(ctx context.Context) ([]*entity.APIKey, error) {
+	return uc.apiKeys.List(ctx)
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+var ErrInvalidRate = errors.New(`rate must be between 0 and 1`)
+
+type TaxSettings interface {
+	GetSettings(ctx context.Context, tenantId string) (*entity.TaxSettings, error)
+	SaveSettings(ctx context.Context, tenantId string, settings *entity.TaxSettings) error
+}
+
+type ManageTaxSettingsUseCase struct {
+	settings TaxSettings
+}
+
+func NewManageTaxSettingsUseCase(settings TaxSettings) *ManageTaxSettingsUseCase {
+	return &ManageTaxSettingsUseCase{
+		settings: settings,
+	}
+}
+
+func (uc *ManageTaxSettingsUseCase) Get(ctx context.Context) (*entity.TaxSettings, error) {
+	return uc.settings.GetSettings(ctx, tenantFromContext(ctx))
+}
+
+func (uc *ManageTaxSettingsUseCase) Save(ctx context.Context, settings *entity.TaxSettings) error {
+	verr := &ValidationError{}
+	seen := make(map[string]bool, len(settings.Overrides))
+	for i, o := range settings.Overrides {
+		field := fmt.Sprintf("overrides[%d]", i)
+		if o.JurisdictionName == "" {
+			verr.Add(field+".jurisdiction", ErrMissingField)
+		} else if seen[o.JurisdictionName] {
+			verr.Add(field+".jurisdiction", errors.New(`duplicate jurisdiction`))
+		}
+		seen[o.JurisdictionName] = true
+
+		for name, rate := range map[string]decimal.Decimal{
+			"stateRate": o.StateRate, "countyRate": o.CountyRate,
+			"cityRate": o.CityRate, "specialRate": o.SpecialRate,
+		} {
+			if rate.IsNegative() || rate.GreaterThan(decimal.NewFromInt(1)) {
+				verr.Add(field+"."+name, ErrInvalidRate)
+			}
+		}
+	}
+	if err := verr.OrNil(); err != nil {
+		return err
+	}
+
+	return uc.settings.SaveSettings(ctx, tenantFromContext(ctx), settings)
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+// QuoteOrderUseCase calculates the tax for a delivery without recording
+// an order.
+type QuoteOrderUseCase struct {
+	geocodingService GeocodingService
+	taxRates         TaxRates
+}
+
+func NewQuoteOrderUseCase(geocodingService GeocodingService, taxRates TaxRates) *QuoteOrderUseCase {
+	return &QuoteOrderUseCase{
+		geocodingService: geocodingService,
+		taxRates:         taxRates,
+	}
+}
+
+func (uc *QuoteOrderUseCase) Execute(ctx context.Context,
+	latitude, longitude float64, subtotal decimal.Decimal) (*entity.Quote, error) {
+	verr := &ValidationError{}
+	if latitude < -90 || latitude > 90 {
+		verr.Add("latitude", ErrInvalidLatitude)
+	}
+	if longitude < -180 || longitude > 180 {
+		verr.Add("longitude", ErrInvalidLongitude)
+	}
+	if subtotal.IsNegative() {
+		verr.Add("subtotal", ErrInvalidSubtotal)
+	}
+	if err := verr.OrNil(); err != nil {
+		return nil, err
+	}
+
+	juris, compositeTaxRate, taxBreakdown, err := resolveTax(ctx,
+		uc.geocodingService, uc.taxRates, tenantFromContext(ctx), latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	taxAmount := subtotal.Mul(compositeTaxRate).Round(2)
+
+	return &entity.Quote{
+		Latitude:         latitude,
+		Longitude:        longitude,
+		Subtotal:         subtotal,
+		CompositeTaxRate: compositeTaxRate,
+		TaxAmount:        taxAmount,
+		TotalAmount:      subtotal.Add(taxAmount),
+		Breakdown:        *taxBreakdown,
+		Jurisdiction:     *juris,
+	}, nil
+}
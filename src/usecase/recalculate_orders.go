@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/shopspring/decimal"
+)
+
+// recalcBatchSize orders are updated per transaction.
+const recalcBatchSize = 500
+
+// RecalculationResult summarizes a recalculation run.
+type RecalculationResult struct {
+	Scanned  int
+	Changed  int
+	Failed   int
+	TaxDelta decimal.Decimal
+}
+
+// RecalculateOrdersUseCase reapplies the current tax rates, including
+// tenant overrides and exemptions, to stored orders. Orders keep the
+// jurisdiction they were geocoded to, so nothing is geocoded again.
+type RecalculateOrdersUseCase struct {
+	orders   Orders
+	taxRates TaxRates
+	logger   *slog.Logger
+}
+
+func NewRecalculateOrdersUseCase(orders Orders, taxRates TaxRates, logger *slog.Logger) *RecalculateOrdersUseCase {
+	return &RecalculateOrdersUseCase{
+		orders:   orders,
+		taxRates: taxRates,
+		logger:   logger,
+	}
+}
+
+// Execute recalculates the orders of params.TenantId matching params. A dry
+// run reports what would change without writing.
+func (uc *RecalculateOrdersUseCase) Execute(ctx context.Context, params entity.ListParams,
+	dryRun bool, progress func(RecalculationResult)) (*RecalculationResult, error) {
+	result := &RecalculationResult{TaxDelta: decimal.Zero}
+	rates := make(map[entity.Jurisdiction]*taxRate)
+	batch := make([]*entity.Order, 0, recalcBatchSize)
+
+	flush := func() error {
+		if len(batch) > 0 && !dryRun {
+			if err := uc.orders.UpdateTaxes(ctx, params.TenantId, batch); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		if progress != nil {
+			progress(*result)
+		}
+		return nil
+	}
+
+	err := uc.orders.Stream(ctx, params, func(order *entity.Order) error {
+		result.Scanned++
+
+		rate, err := uc.rate(ctx, rates, params.TenantId, order.Jurisdiction)
+		if errors.Is(err, entity.ErrNotFound) {
+			result.Failed++
+			uc.logger.DebugContext(ctx, "No tax rate for order", "order_id", order.Id, "error", err)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		taxAmount := order.Subtotal.Mul(rate.composite).Round(2)
+		if taxAmount.Equal(order.TaxAmount) && rate.composite.Equal(order.CompositeTaxRate) &&
+			sameBreakdown(rate.breakdown, order.Breakdown) {
+			return nil
+		}
+
+		result.Changed++
+		result.TaxDelta = result.TaxDelta.Add(taxAmount.Sub(order.TaxAmount))
+
+		order.CompositeTaxRate = rate.composite
+		order.TaxAmount = taxAmount
+		order.TotalAmount = order.Subtotal.Add(taxAmount)
+		order.Breakdown = rate.breakdown
+		batch = append(batch, order)
+
+		if len(batch) >= recalcBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	return result, flush()
+}
+
+type taxRate struct {
+	composite decimal.Decimal
+	breakdown entity.TaxBreakdown
+}
+
+// rate looks each jurisdiction up once per run; a nil entry records one
+// without a rate.
+func (uc *RecalculateOrdersUseCase) rate(ctx context.Context, rates map[entity.Jurisdiction]*taxRate,
+	tenantId string, jurisdiction entity.Jurisdiction) (*taxRate, error) {
+	if rate, ok := rates[jurisdiction]; ok {
+		if rate == nil {
+			return nil, entity.ErrNotFound
+		}
+		return rate, nil
+	}
+	composite, breakdown, err := uc.taxRates.Get(ctx, tenantId, &jurisdiction)
+	if errors.Is(err, entity.ErrNotFound) {
+		rates[jurisdiction] = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rate := &taxRate{composite: composite, breakdown: *breakdown}
+	rates[jurisdiction] = rate
+	return rate, nil
+}
+
+func sameBreakdown(a, b entity.TaxBreakdown) bool {
+	return a.StateRate.Equal(b.StateRate) && a.CountyRate.Equal(b.CountyRate) &&
+		a.CityRate.Equal(b.CityRate) && a.SpecialRate.Equal(b.SpecialRate)
+}
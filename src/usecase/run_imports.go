@@ -0,0 +1,315 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// requeueTimeout bounds handing a job back to the queue at shutdown, when
+// the drain deadline may already have passed.
+const requeueTimeout = 2 * time.Second
+
+// ImportRunner works through the import queue. Any number of runners may
+// poll the same queue; each claimed job is leased to one of them and
+// renewed while it runs. A job whose runner died is reclaimed once its
+// lease expires and resumes after its last checkpoint.
+type ImportRunner struct {
+	jobs     ImportJobs
+	storage  FileStorage
+	importer *ImportOrdersUseCase
+	hub      *ImportProgressHub
+	logger   *slog.Logger
+
+	instance     string
+	pollInterval time.Duration
+	lease        time.Duration
+
+	mu      sync.Mutex
+	running map[uuid.UUID]context.CancelCauseFunc
+}
+
+func NewImportRunner(jobs ImportJobs, storage FileStorage, importer *ImportOrdersUseCase,
+	hub *ImportProgressHub, pollInterval, lease time.Duration, logger *slog.Logger) *ImportRunner {
+	hostname, _ := os.Hostname()
+	return &ImportRunner{
+		jobs:         jobs,
+		storage:      storage,
+		importer:     importer,
+		hub:          hub,
+		logger:       logger,
+		instance:     fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+		pollInterval: pollInterval,
+		lease:        lease,
+		running:      make(map[uuid.UUID]context.CancelCauseFunc),
+	}
+}
+
+// Run processes up to concurrency jobs at a time until ctx is cancelled.
+// Running jobs then get drainTimeout to checkpoint the rows they have
+// started and go back to the queue; Run returns once they are done.
+func (r *ImportRunner) Run(ctx context.Context, concurrency int, drainTimeout time.Duration) {
+	// Jobs write their checkpoints with work, which outlives ctx by up to
+	// drainTimeout.
+	work, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-work.Done():
+			return
+		}
+		timer := time.NewTimer(drainTimeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			r.logger.Warn("Import drain timed out, interrupting running imports")
+			cancel()
+		case <-work.Done():
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range max(concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.poll(ctx, work)
+		}()
+	}
+	wg.Wait()
+}
+
+func (r *ImportRunner) poll(ctx, work context.Context) {
+	// A job drained at shutdown is back in the queue; it must not be
+	// claimed again by this runner.
+	for ctx.Err() == nil {
+		job, workerId, err := r.claim(ctx)
+		if err == nil {
+			r.process(ctx, work, job, workerId)
+			continue
+		}
+		if !errors.Is(err, entity.ErrNotFound) && ctx.Err() == nil {
+			r.logger.Error("Failed to claim import job", "error", err)
+		}
+
+		select {
+		case <-time.After(r.pollInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// claim leases the next job under a fresh worker id, so a runner that lost
+// a lease can never write to the job again even if it reclaims it later.
+func (r *ImportRunner) claim(ctx context.Context) (*entity.ImportJob, string, error) {
+	workerId := r.instance + "/" + uuid.NewString()
+	job, err := r.jobs.Claim(ctx, workerId, r.lease)
+	return job, workerId, err
+}
+
+// process runs job until it ends or, once ctx is cancelled, until it has
+// drained. Its database writes use work.
+func (r *ImportRunner) process(ctx, work context.Context, job *entity.ImportJob, workerId string) {
+	r.logger.Info("Import claimed", "import_id", job.Id, "worker_id", workerId,
+		"checkpoint_row", job.CheckpointRow)
+
+	jobCtx, cancel := context.WithCancelCause(work)
+	defer cancel(nil)
+
+	r.track(job.Id, cancel)
+	defer r.untrack(job.Id)
+
+	r.hub.start(job)
+	defer r.release(work, job)
+
+	go r.heartbeat(jobCtx, cancel, job.Id, workerId)
+
+	err := r.run(jobCtx, job, workerId, ctx.Done())
+	var quotaErr *QuotaExceededError
+	switch {
+	case err == nil:
+		err := r.jobs.Finish(work, job.Id, workerId, entity.ImportCompleted, "")
+		if errors.Is(err, entity.ErrLeaseLost) {
+			r.stopped(work, job)
+			return
+		}
+		if err != nil {
+			r.logger.Error("Failed to complete import", "import_id", job.Id, "error", err)
+			return
+		}
+		if err := r.storage.Delete(work, job.FileKey); err != nil {
+			r.logger.Warn("Failed to delete import upload", "import_id", job.Id, "error", err)
+		}
+		r.logger.Info("Import finished", "import_id", job.Id)
+	case errors.Is(err, entity.ErrLeaseLost):
+		r.stopped(work, job)
+	case errors.Is(err, errImportDrained):
+		r.logger.Info("Import drained for shutdown", "import_id", job.Id)
+		r.requeue(work, job, workerId)
+	case errors.As(err, &quotaErr):
+		// Waiting for the quota here would hold this worker and the lease
+		// until midnight with nothing to show for it.
+		r.logger.Warn("Import postponed until the geocoding quota resets",
+			"import_id", job.Id, "resume_at", quotaErr.ResetAt)
+		r.postpone(work, job, workerId, quotaErr)
+	case work.Err() != nil:
+		// The drain timed out. Rows after the last checkpoint are
+		// replayed by whoever resumes the job.
+		r.logger.Warn("Import interrupted at shutdown", "import_id", job.Id)
+		r.requeue(work, job, workerId)
+	default:
+		r.logger.Error("Import failed", "import_id", job.Id, "error", err)
+		if err := r.jobs.Finish(work, job.Id, workerId, entity.ImportFailed, err.Error()); err != nil {
+			r.logger.Error("Failed to mark import as failed", "import_id", job.Id, "error", err)
+		}
+	}
+}
+
+// stopped handles a job taken away from this runner: it was paused or
+// cancelled, or its lease expired and another runner claimed it.
+func (r *ImportRunner) stopped(ctx context.Context, job *entity.ImportJob) {
+	current, err := r.jobs.Get(ctx, job.TenantId, job.Id)
+	if err != nil {
+		r.logger.Error("Import stopped, failed to load its status", "import_id", job.Id, "error", err)
+		return
+	}
+	r.logger.Info("Import stopped", "import_id", job.Id, "status", current.Status)
+
+	// The last chunk may have been committed after the canceller's
+	// rollback, so roll back once more now that nothing else is inserted.
+	if current.Status == entity.ImportCancelled && current.RolledBack {
+		if err := rollbackImport(ctx, r.importer.orders, current, r.logger); err != nil {
+			r.logger.Error("Failed to roll back import", "import_id", job.Id, "error", err)
+		}
+	}
+}
+
+// requeue hands a job interrupted by shutdown back to the queue, so
+// another replica picks it up without waiting for the lease to expire.
+func (r *ImportRunner) requeue(ctx context.Context, job *entity.ImportJob, workerId string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), requeueTimeout)
+	defer cancel()
+
+	if err := r.jobs.Release(ctx, job.Id, workerId); err != nil && !errors.Is(err, entity.ErrLeaseLost) {
+		r.logger.Warn("Failed to requeue import, it resumes once its lease expires",
+			"import_id", job.Id, "error", err)
+	}
+}
+
+// postpone hands a job that ran out of geocoding quota back to the queue
+// until the quota resets.
+func (r *ImportRunner) postpone(ctx context.Context, job *entity.ImportJob, workerId string,
+	quotaErr *QuotaExceededError) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), requeueTimeout)
+	defer cancel()
+
+	err := r.jobs.Postpone(ctx, job.Id, workerId, quotaErr.ResetAt, quotaErr.Error())
+	if err != nil && !errors.Is(err, entity.ErrLeaseLost) {
+		r.logger.Error("Failed to postpone import, it resumes once its lease expires",
+			"import_id", job.Id, "error", err)
+	}
+}
+
+func (r *ImportRunner) run(ctx context.Context, job *entity.ImportJob, workerId string,
+	draining <-chan struct{}) error {
+	file, err := r.storage.Open(ctx, job.FileKey)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = r.importer.Execute(ctx, job, file, &jobReporter{ctx: ctx, runner: r, job: job,
+		workerId: workerId, draining: draining})
+	if cause := context.Cause(ctx); err != nil && errors.Is(cause, entity.ErrLeaseLost) {
+		return cause
+	}
+	return err
+}
+
+// release publishes the state a job was left in once this runner is done
+// with it.
+func (r *ImportRunner) release(ctx context.Context, job *entity.ImportJob) {
+	current, err := r.jobs.Get(context.WithoutCancel(ctx), job.TenantId, job.Id)
+	if err != nil {
+		r.logger.Error("Failed to load final state of import", "import_id", job.Id, "error", err)
+		current = job
+	}
+	r.hub.stop(current)
+}
+
+func (r *ImportRunner) track(id uuid.UUID, cancel context.CancelCauseFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.running[id] = cancel
+}
+
+func (r *ImportRunner) untrack(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.running, id)
+}
+
+// interrupt stops job id right away if this runner holds it. The caller
+// must already have revoked its lease.
+func (r *ImportRunner) interrupt(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cancel, ok := r.running[id]; ok {
+		cancel(entity.ErrLeaseLost)
+	}
+}
+
+// heartbeat renews the lease between checkpoints, which can be far apart
+// while geocoding is throttled. It cancels the job once the lease is lost,
+// which is also how a pause or cancel on another replica reaches it, so it
+// beats at least every poll interval.
+func (r *ImportRunner) heartbeat(ctx context.Context, cancel context.CancelCauseFunc,
+	id uuid.UUID, workerId string) {
+	ticker := time.NewTicker(min(r.lease/3, r.pollInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := r.jobs.Heartbeat(ctx, id, workerId, r.lease)
+			if errors.Is(err, entity.ErrLeaseLost) {
+				cancel(err)
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				r.logger.Warn("Failed to renew import lease", "import_id", id, "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+type jobReporter struct {
+	ctx      context.Context
+	runner   *ImportRunner
+	job      *entity.ImportJob
+	workerId string
+	draining <-chan struct{}
+}
+
+func (rep *jobReporter) Progress(progress entity.ImportProgress) {
+	rep.runner.hub.publish(rep.job.Id, progress)
+}
+
+func (rep *jobReporter) Checkpoint(progress entity.ImportProgress) error {
+	return rep.runner.jobs.Checkpoint(rep.ctx, rep.job.Id, rep.workerId, progress, rep.runner.lease)
+}
+
+func (rep *jobReporter) Draining() <-chan struct{} {
+	return rep.draining
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// resolveTax geocodes a delivery location and looks up the composite rate
+// for it, translating lower-level failures into the usecase domain errors.
+func resolveTax(ctx context.Context, geocodingService GeocodingService, taxRates TaxRates,
+	tenantId string, latitude, longitude float64) (*entity.Jurisdiction, decimal.Decimal, *entity.TaxBreakdown, error) {
+	juris, err := geocodingService.GetJurisdiction(ctx, latitude, longitude)
+	if err != nil {
+		// A caller that gave up, or an import that was paused, says
+		// nothing about the geocoder.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, decimal.Zero, nil, ctxErr
+		}
+		if errors.Is(err, entity.ErrNotFound) {
+			return nil, decimal.Zero, nil, fmt.Errorf("%w: %v", ErrJurisdictionNotFound, err)
+		}
+		// %w twice keeps the geocoder's own error, such as an exhausted
+		// quota, visible to errors.Is and errors.As.
+		return nil, decimal.Zero, nil, fmt.Errorf("%w: %w", ErrGeocodingUnavailable, err)
+	}
+
+	if juris.State != "New York" {
+		return nil, decimal.Zero, nil, fmt.Errorf("%w (got: %s)", ErrOutOfState, juris.State)
+	}
+
+	compositeTaxRate, taxBreakdown, err := taxRates.Get(ctx, tenantId, juris)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			return nil, decimal.Zero, nil, fmt.Errorf("%w: %v", ErrJurisdictionNotFound, err)
+		}
+		return nil, decimal.Zero, nil, err
+	}
+
+	return juris, compositeTaxRate, taxBreakdown, nil
+}
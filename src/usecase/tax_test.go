@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestResolveTaxErrors(t *testing.T) {
+	geocoderErr := errors.New("connection refused")
+	tests := []struct {
+		name     string
+		geocoder error
+		want     []error
+	}{
+		{"not found", entity.ErrNotFound, []error{ErrJurisdictionNotFound}},
+		{"unavailable", geocoderErr, []error{ErrGeocodingUnavailable, geocoderErr}},
+		{"quota", &QuotaExceededError{}, []error{ErrGeocodingUnavailable, ErrGeocodingQuotaExceeded}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failing := geocoderFunc(func(context.Context, float64, float64) (*entity.Jurisdiction, error) {
+				return nil, tt.geocoder
+			})
+			_, _, _, err := resolveTax(context.Background(), failing, flatRate{}, entity.DefaultTenant, 40.7, -74)
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("err = %v, want it to match %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestQuoteReportsQuotaReset(t *testing.T) {
+	governor := NewGeocodingGovernor(inNewYork, 1000, 1, time.UTC, slog.New(slog.DiscardHandler))
+	defer governor.Close()
+	uc := NewQuoteOrderUseCase(governor, flatRate{})
+
+	if _, err := uc.Execute(context.Background(), 40.7, -74, decimal.NewFromInt(10)); err != nil {
+		t.Fatalf("first quote: %v", err)
+	}
+
+	_, err := uc.Execute(context.Background(), 40.7, -74, decimal.NewFromInt(10))
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("err = %v, want a QuotaExceededError", err)
+	}
+	if until := time.Until(quotaErr.ResetAt); until <= 0 || until > 24*time.Hour {
+		t.Errorf("quota resets in %s, want within the next day", until)
+	}
+}
@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+)
+
+// tenantFromContext returns the tenant of the authenticated caller, or the
+// default tenant when the request was not authenticated.
+func tenantFromContext(ctx context.Context) string {
+	if principal, ok := entity.PrincipalFromContext(ctx); ok && principal.TenantId != "" {
+		return principal.TenantId
+	}
+	return entity.DefaultTenant
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"InstantWellnessKits/src/entity"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ImportEventType string
+
+const (
+	ImportEventProgress ImportEventType = "progress"
+	ImportEventRowError ImportEventType = "row_error"
+	ImportEventSummary  ImportEventType = "summary"
+)
+
+// ImportEvent carries the job state for progress and summary events, or
+// one failed row for row_error events.
+type ImportEvent struct {
+	Type     ImportEventType
+	Job      *entity.ImportJob
+	RowError *entity.ImportRowError
+}
+
+// WatchImportUseCase streams the progress of an import job. A job running
+// in this process is followed live; one running on another replica (or
+// not running at all) is polled from the database, which reflects its last
+// checkpoint.
+type WatchImportUseCase struct {
+	jobs         ImportJobs
+	hub          *ImportProgressHub
+	pollInterval time.Duration
+}
+
+func NewWatchImportUseCase(jobs ImportJobs, hub *ImportProgressHub, pollInterval time.Duration) *WatchImportUseCase {
+	return &WatchImportUseCase{
+		jobs:         jobs,
+		hub:          hub,
+		pollInterval: pollInterval,
+	}
+}
+
+// Execute calls emit with the current state right away and then with every
+// change until the job finishes, emit fails or ctx is cancelled.
+func (uc *WatchImportUseCase) Execute(ctx context.Context, id uuid.UUID, emit func(ImportEvent) error) error {
+	tenantId := tenantFromContext(ctx)
+
+	job, err := uc.jobs.Get(ctx, tenantId, id)
+	if err != nil {
+		return err
+	}
+
+	stream := &importEventStream{emit: emit}
+	for {
+		if job != nil {
+			if finished, err := stream.send(job); finished || err != nil {
+				return err
+			}
+		}
+
+		if w, ok := uc.hub.watch(id); ok {
+			if finished, err := stream.follow(ctx, w); finished || err != nil {
+				return err
+			}
+		} else {
+			select {
+			case <-time.After(uc.pollInterval):
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		job, err = uc.jobs.Get(ctx, tenantId, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+type importEventStream struct {
+	emit       func(ImportEvent) error
+	sentErrors int
+	last       *entity.ImportJob
+}
+
+// follow relays a job running in this process until it stops here.
+func (s *importEventStream) follow(ctx context.Context, w *importWatch) (bool, error) {
+	defer w.close()
+
+	for {
+		select {
+		case <-w.changed:
+			if finished, err := s.send(w.current()); finished || err != nil {
+				return finished, err
+			}
+		case <-w.live.done:
+			return s.send(w.current())
+		case <-ctx.Done():
+			return true, nil
+		}
+	}
+}
+
+// send emits the row errors not sent yet and then a progress event, or the
+// summary once the job has finished. It reports whether the stream is over.
+func (s *importEventStream) send(job *entity.ImportJob) (bool, error) {
+	// A job resumed from a checkpoint may report fewer samples than were
+	// already sent live.
+	s.sentErrors = min(s.sentErrors, len(job.ErrorSamples))
+	for i := range job.ErrorSamples[s.sentErrors:] {
+		rowError := job.ErrorSamples[s.sentErrors+i]
+		if err := s.emit(ImportEvent{Type: ImportEventRowError, RowError: &rowError}); err != nil {
+			return true, err
+		}
+	}
+	s.sentErrors = len(job.ErrorSamples)
+
+	if job.Status.Finished() {
+		return true, s.emit(ImportEvent{Type: ImportEventSummary, Job: job})
+	}
+
+	if s.last != nil && s.last.Status == job.Status && s.last.UpdatedAt.Equal(job.UpdatedAt) {
+		return false, nil
+	}
+	s.last = job
+
+	return false, s.emit(ImportEvent{Type: ImportEventProgress, Job: job})
+}